@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/spf13/pflag"
 
@@ -11,15 +13,65 @@ import (
 )
 
 var (
-	specFile string
-	specURL  string
-	modeStr  string
+	specFile   string
+	specURL    string
+	modeStr    string
+	listenAddr string
+	basePath   string
+	keepAlive  time.Duration
+
+	allowExternalRefs bool
+	allowedRefBases   []string
+
+	baseURL         string
+	serverIndex     int
+	serverVariables map[string]string
+
+	credentialsFile string
+	headers         []string
+
+	filter openapimcp.OperationFilter
+
+	validateResponses bool
+	sessionCookies    bool
+	fileRoots         []string
+	toolNaming        string
+	bodyCollisions    string
+
+	descriptionTemplateFile string
+	descriptionMaxLength    int
 )
 
 func init() {
 	pflag.StringVarP(&specFile, "spec-file", "f", "", "Path to a local OpenAPI spec file (JSON or YAML).")
 	pflag.StringVarP(&specURL, "spec-url", "u", "", "URL to a remote OpenAPI spec file (JSON or YAML).")
-	pflag.StringVarP(&modeStr, "mode", "m", "stdio", "MCP server mode: 'stdio' or 'sse'. (default: stdio)")
+	pflag.StringVarP(&modeStr, "mode", "m", "stdio", "MCP server mode: 'stdio', 'sse' or 'streamable-http'. (default: stdio)")
+	pflag.StringVar(&listenAddr, "listen-addr", openapimcp.DefaultListenAddr, "Address to listen on in 'sse' and 'streamable-http' modes.")
+	pflag.StringVar(&basePath, "base-path", "", "Path prefix of the SSE endpoints, or path of the streamable HTTP endpoint (default /mcp), e.g. /mcp.")
+	pflag.DurationVar(&keepAlive, "keep-alive", 0, "Interval between keep-alive pings in 'sse' and 'streamable-http' modes, e.g. 30s. Disabled when 0.")
+	pflag.BoolVar(&allowExternalRefs, "allow-external-refs", false, "Resolve $refs to other files or URLs relative to the spec.")
+	pflag.StringSliceVar(&allowedRefBases, "allowed-ref-base", nil, "Directory or http(s) URL prefix external $refs may be loaded from. Repeatable. Defaults to the spec's own directory or host.")
+	pflag.StringVar(&baseURL, "base-url", "", "Base URL of the API, overrides the servers declared in the spec.")
+	pflag.IntVar(&serverIndex, "server-index", 0, "Index of the server in the spec's servers list to send requests to.")
+	pflag.StringToStringVar(&serverVariables, "server-var", nil, "Server variable override as name=value, e.g. --server-var region=eu. Repeatable.")
+	pflag.StringVar(&credentialsFile, "credentials-file", "", "JSON or YAML file with credentials keyed by security scheme name. Environment variables OPENAPI_MCP_<SCHEME>_{API_KEY,TOKEN,USERNAME,PASSWORD,CLIENT_ID,CLIENT_SECRET,REFRESH_TOKEN} take precedence.")
+	pflag.StringArrayVarP(&headers, "header", "H", nil, "Extra header sent with every API request, as 'Name: value'. Repeatable.")
+	pflag.StringSliceVar(&filter.IncludeTags, "include-tag", nil, "Only expose operations with one of these tags. Repeatable.")
+	pflag.StringSliceVar(&filter.ExcludeTags, "exclude-tag", nil, "Hide operations with any of these tags. Repeatable.")
+	pflag.StringVar(&filter.IncludeOperationID, "include-operation-id", "", "Only expose operations whose operationId matches this regular expression.")
+	pflag.StringVar(&filter.ExcludeOperationID, "exclude-operation-id", "", "Hide operations whose operationId matches this regular expression.")
+	pflag.StringSliceVar(&filter.IncludeMethods, "include-method", nil, "Only expose operations with these HTTP methods, e.g. GET. Repeatable.")
+	pflag.StringSliceVar(&filter.ExcludeMethods, "exclude-method", nil, "Hide operations with these HTTP methods. Repeatable.")
+	pflag.StringSliceVar(&filter.IncludePaths, "include-path", nil, "Only expose operations whose path matches this glob, e.g. /billing/**. Repeatable.")
+	pflag.StringSliceVar(&filter.ExcludePaths, "exclude-path", nil, "Hide operations whose path matches this glob. Repeatable.")
+	pflag.BoolVar(&filter.ExcludeDeprecated, "exclude-deprecated", false, "Hide operations marked as deprecated.")
+	pflag.BoolVar(&validateResponses, "validate-responses", false, "Check API responses against the spec and report mismatches as warnings in the tool result.")
+	pflag.BoolVar(&sessionCookies, "session-cookies", false, "Keep cookies set by the API and send them on later calls of the same MCP session.")
+	pflag.StringSliceVar(&fileRoots, "file-root", nil, "Directory that file:// arguments of binary fields may be read from. Repeatable. File references are disabled when unset.")
+	pflag.StringVar(&toolNaming, "tool-naming", "operation-id", "How tool names are derived: 'operation-id', 'method-path' (e.g. get_pets_petId) or 'tag-prefixed' (operation name prefixed with its first tag).")
+	pflag.StringVar(&descriptionTemplateFile, "description-template", "", "File with a Go text/template overriding the generated tool descriptions.")
+	pflag.IntVar(&descriptionMaxLength, "description-max-length", 0, "Truncate tool descriptions to this many characters. 0 means no limit.")
+	pflag.StringVar(&bodyCollisions, "body-collisions", "prefix", "How request body fields named like a parameter are exposed: 'prefix' (body_<name>) or 'nest' (under a body object).")
 	pflag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Usage of %s:\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "Generates and runs an MCP server based on an OpenAPI specification.\n\n")
@@ -27,6 +79,8 @@ func init() {
 		fmt.Fprintf(os.Stderr, "\nExample:\n")
 		fmt.Fprintf(os.Stderr, "  %s -f ./path/to/your/openapi.yaml\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s -u https://petstore3.swagger.io/api/v3/openapi.json --mode sse\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -f ./path/to/your/openapi.yaml --mode streamable-http --listen-addr :8080\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -f ./path/to/your/openapi.yaml --include-method GET --include-tag Billing\n", os.Args[0])
 	}
 }
 
@@ -50,23 +104,85 @@ func main() {
 		specSource = specURL
 	}
 
-	// var mcpMode openapimcp.ServerMode
-	// switch modeStr {
-	// case "stdio":
-	// 	mcpMode = openapimcp.StdIO
-	// case "sse":
-	// 	mcpMode = openapimcp.SSE
-	// default:
-	// 	log.Printf("Error: Invalid mode '%s'. Allowed modes are 'stdio' or 'sse'.", modeStr)
-	// 	pflag.Usage()
-	// 	os.Exit(1)
-	// }
+	headerMap := make(map[string]string, len(headers))
+	for _, header := range headers {
+		name, value, ok := strings.Cut(header, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			log.Printf("Error: Invalid header '%s'. Expected 'Name: value'.", header)
+			pflag.Usage()
+			os.Exit(1)
+		}
+		headerMap[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
 
-	// log.Printf("Initializing MCP server generator with spec: %s, mode: %s", specSource, mcpMode)
+	var mcpMode openapimcp.ServerMode
+	switch modeStr {
+	case "stdio":
+		mcpMode = openapimcp.StdIO
+	case "sse":
+		mcpMode = openapimcp.SSE
+	case "streamable-http":
+		mcpMode = openapimcp.StreamableHTTP
+	default:
+		log.Printf("Error: Invalid mode '%s'. Allowed modes are 'stdio', 'sse' or 'streamable-http'.", modeStr)
+		pflag.Usage()
+		os.Exit(1)
+	}
+
+	var naming openapimcp.ToolNaming
+	switch toolNaming {
+	case "operation-id":
+		naming = openapimcp.OperationIDNaming
+	case "method-path":
+		naming = openapimcp.MethodPathNaming
+	case "tag-prefixed":
+		naming = openapimcp.TagPrefixedNaming
+	default:
+		log.Printf("Error: Invalid tool naming '%s'. Allowed namings are 'operation-id', 'method-path' or 'tag-prefixed'.", toolNaming)
+		pflag.Usage()
+		os.Exit(1)
+	}
+
+	var collisionStrategy openapimcp.BodyCollisionStrategy
+	switch bodyCollisions {
+	case "prefix":
+		collisionStrategy = openapimcp.PrefixBodyFields
+	case "nest":
+		collisionStrategy = openapimcp.NestBodyFields
+	default:
+		log.Printf("Error: Invalid body collision strategy '%s'. Allowed strategies are 'prefix' or 'nest'.", bodyCollisions)
+		pflag.Usage()
+		os.Exit(1)
+	}
 
 	config := openapimcp.GeneratorConfig{
 		SpecSource: specSource,
-		ServerMode: openapimcp.StdIO,
+		ServerMode: mcpMode,
+		ListenAddr: listenAddr,
+		BasePath:   basePath,
+		KeepAlive:  keepAlive,
+
+		AllowExternalRefs: allowExternalRefs,
+		AllowedRefBases:   allowedRefBases,
+
+		BaseURL:         baseURL,
+		ServerIndex:     serverIndex,
+		ServerVariables: serverVariables,
+
+		CredentialsFile: credentialsFile,
+		Headers:         headerMap,
+
+		Filter: filter,
+
+		ValidateResponses: validateResponses,
+		SessionCookies:    sessionCookies,
+		FileRoots:         fileRoots,
+
+		ToolNaming:     naming,
+		BodyCollisions: collisionStrategy,
+
+		DescriptionTemplateFile: descriptionTemplateFile,
+		DescriptionMaxLength:    descriptionMaxLength,
 	}
 
 	if err := openapimcp.RunFromSpec(config); err != nil {
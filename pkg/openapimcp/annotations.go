@@ -0,0 +1,38 @@
+package openapimcp
+
+import (
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// toolAnnotations derives MCP tool annotations from the HTTP semantics of the
+// operation's method and uses its summary as the title. Values set in the
+// x-mcp-annotations extension take precedence.
+func toolAnnotations(method string, op *openapi3.Operation) mcp.ToolAnnotation {
+	annotations := mcp.ToolAnnotation{
+		Title: op.Summary,
+	}
+
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		annotations.ReadOnlyHint = boolPtr(true)
+	case http.MethodDelete:
+		annotations.ReadOnlyHint = boolPtr(false)
+		annotations.DestructiveHint = boolPtr(true)
+		annotations.IdempotentHint = boolPtr(true)
+	case http.MethodPut:
+		annotations.ReadOnlyHint = boolPtr(false)
+		annotations.IdempotentHint = boolPtr(true)
+	default:
+		annotations.ReadOnlyHint = boolPtr(false)
+	}
+
+	applyAnnotationExtension(&annotations, op.Extensions)
+	return annotations
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
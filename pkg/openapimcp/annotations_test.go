@@ -0,0 +1,71 @@
+package openapimcp
+
+import (
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToolAnnotations(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		op       *openapi3.Operation
+		expected mcp.ToolAnnotation
+	}{
+		{
+			name:     "GET is read-only",
+			method:   "GET",
+			op:       &openapi3.Operation{Summary: "List pets"},
+			expected: mcp.ToolAnnotation{Title: "List pets", ReadOnlyHint: boolPtr(true)},
+		},
+		{
+			name:     "HEAD is read-only",
+			method:   "HEAD",
+			op:       &openapi3.Operation{},
+			expected: mcp.ToolAnnotation{ReadOnlyHint: boolPtr(true)},
+		},
+		{
+			name:   "DELETE is destructive and idempotent",
+			method: "DELETE",
+			op:     &openapi3.Operation{Summary: "Delete a pet"},
+			expected: mcp.ToolAnnotation{
+				Title:           "Delete a pet",
+				ReadOnlyHint:    boolPtr(false),
+				DestructiveHint: boolPtr(true),
+				IdempotentHint:  boolPtr(true),
+			},
+		},
+		{
+			name:     "PUT is idempotent",
+			method:   "PUT",
+			op:       &openapi3.Operation{},
+			expected: mcp.ToolAnnotation{ReadOnlyHint: boolPtr(false), IdempotentHint: boolPtr(true)},
+		},
+		{
+			name:     "POST only modifies",
+			method:   "POST",
+			op:       &openapi3.Operation{},
+			expected: mcp.ToolAnnotation{ReadOnlyHint: boolPtr(false)},
+		},
+		{
+			name:   "extension overrides derived hints",
+			method: "POST",
+			op: &openapi3.Operation{
+				Summary: "Search pets",
+				Extensions: map[string]any{
+					"x-mcp-annotations": map[string]any{"title": "Search", "readOnlyHint": true, "openWorldHint": false},
+				},
+			},
+			expected: mcp.ToolAnnotation{Title: "Search", ReadOnlyHint: boolPtr(true), OpenWorldHint: boolPtr(false)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, toolAnnotations(tt.method, tt.op))
+		})
+	}
+}
@@ -0,0 +1,358 @@
+package openapimcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Request body media types tools can send, besides JSON
+const (
+	mediaTypeForm      = "application/x-www-form-urlencoded"
+	mediaTypeMultipart = "multipart/form-data"
+)
+
+// bodyArgument is the tool argument carrying a request body that is not
+// flattened into one argument per property
+const bodyArgument = "body"
+
+// requestBody is an encoded request body. Bodies are opened anew for every
+// attempt so that file content can be streamed and retried.
+type requestBody struct {
+	contentType string
+	open        func() (io.Reader, int64, error) // Returns the body and its length, -1 when unknown
+}
+
+func bytesRequestBody(contentType string, data []byte) *requestBody {
+	return &requestBody{
+		contentType: contentType,
+		open: func() (io.Reader, int64, error) {
+			return bytes.NewReader(data), int64(len(data)), nil
+		},
+	}
+}
+
+// binaryRequestBody sends file content as the whole request body
+func binaryRequestBody(mediaType string, data *binaryData) *requestBody {
+	contentType := mediaType
+	if strings.Contains(contentType, "*") {
+		contentType = data.contentType
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return &requestBody{contentType: contentType, open: data.open}
+}
+
+// requestBodyMedia returns the request body media type the tool sends,
+// preferring JSON over form-urlencoded over multipart over binary bodies. It
+// returns nil when the operation has no body in a supported media type.
+func requestBodyMedia(op *openapi3.Operation) (string, *openapi3.MediaType) {
+	if op.RequestBody == nil || op.RequestBody.Value == nil {
+		return "", nil
+	}
+	content := op.RequestBody.Value.Content
+
+	mediaTypes := make([]string, 0, len(content))
+	for mediaType, media := range content {
+		if media != nil && bodyMediaRank(mediaType, media) > 0 {
+			mediaTypes = append(mediaTypes, mediaType)
+		}
+	}
+	if len(mediaTypes) == 0 {
+		return "", nil
+	}
+
+	sort.SliceStable(mediaTypes, func(i, j int) bool {
+		ri, rj := bodyMediaRank(mediaTypes[i], content[mediaTypes[i]]), bodyMediaRank(mediaTypes[j], content[mediaTypes[j]])
+		if ri != rj {
+			return ri > rj
+		}
+		return mediaTypes[i] < mediaTypes[j]
+	})
+	return mediaTypes[0], content[mediaTypes[0]]
+}
+
+// bodyMediaRank orders the supported request body media types by preference,
+// unsupported media types rank 0
+func bodyMediaRank(mediaType string, media *openapi3.MediaType) int {
+	parsed, _, err := mime.ParseMediaType(mediaType)
+	if err != nil {
+		return 0
+	}
+
+	// Binary bodies are sent as they are and need no schema
+	if isBinaryMedia(mediaType, media) {
+		return 1
+	}
+	if media.Schema == nil {
+		return 0
+	}
+
+	switch {
+	case parsed == "application/json":
+		return 5
+	case strings.Contains(parsed, "json"):
+		return 4
+	case parsed == mediaTypeForm:
+		return 3
+	case parsed == mediaTypeMultipart:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// flattenBody reports whether a body schema can be exposed as one tool
+// argument per property without losing data. Other bodies, such as arrays,
+// primitives, compositions and maps, are passed whole in the body argument.
+func flattenBody(schemaRef *openapi3.SchemaRef) bool {
+	if schemaRef == nil || schemaRef.Value == nil {
+		return false
+	}
+
+	schema := schemaRef.Value
+	if schema.Type != nil && !schema.Type.Is("object") {
+		return false
+	}
+	if len(schema.Properties) == 0 {
+		return false
+	}
+	if len(schema.OneOf) > 0 || len(schema.AnyOf) > 0 || len(schema.AllOf) > 0 || schema.Not != nil {
+		return false
+	}
+	additional := schema.AdditionalProperties
+	return additional.Schema == nil && (additional.Has == nil || !*additional.Has)
+}
+
+// encodeRequestBody encodes the body in the media type. Form and multipart
+// bodies must be objects.
+func encodeRequestBody(mediaType string, media *openapi3.MediaType, body any) (*requestBody, error) {
+	parsed, _, _ := mime.ParseMediaType(mediaType)
+
+	switch parsed {
+	case mediaTypeForm, mediaTypeMultipart:
+		fields, ok := body.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("value must be an object")
+		}
+		if parsed == mediaTypeForm {
+			return bytesRequestBody(mediaTypeForm, encodeFormBody(media, fields)), nil
+		}
+		return encodeMultipartBody(media, fields)
+	default:
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		return bytesRequestBody(mediaType, data), nil
+	}
+}
+
+// encodeFormBody encodes the fields as form-urlencoded pairs, serializing each
+// field according to its encoding object
+func encodeFormBody(media *openapi3.MediaType, fields map[string]any) []byte {
+	pairs := []string{}
+	for _, name := range sortedKeys(fields) {
+		value := fields[name]
+		encoding := media.Encoding[name]
+		if encoding == nil {
+			encoding = &openapi3.Encoding{}
+		}
+
+		if strings.Contains(encoding.ContentType, "json") {
+			if data, err := json.Marshal(value); err == nil {
+				value = string(data)
+			}
+		}
+
+		param := &openapi3.Parameter{
+			Name:          name,
+			In:            openapi3.ParameterInQuery,
+			Style:         encoding.Style,
+			Explode:       encoding.Explode,
+			AllowReserved: encoding.AllowReserved,
+		}
+		pairs = append(pairs, serializeQueryParameter(param, value)...)
+	}
+	return []byte(strings.Join(pairs, "&"))
+}
+
+// encodeMultipartBody encodes each field as a form-data part. Arrays are sent
+// as one part per item and objects as JSON, unless the field's encoding
+// object sets another content type. Bodies with file parts are streamed.
+func encodeMultipartBody(media *openapi3.MediaType, fields map[string]any) (*requestBody, error) {
+	if !hasFileParts(fields) {
+		var buf bytes.Buffer
+		writer := multipart.NewWriter(&buf)
+		if err := writeMultipartFields(writer, media, fields); err != nil {
+			return nil, err
+		}
+		if err := writer.Close(); err != nil {
+			return nil, fmt.Errorf("failed to finish multipart body: %w", err)
+		}
+		return bytesRequestBody(writer.FormDataContentType(), buf.Bytes()), nil
+	}
+
+	boundary := multipart.NewWriter(io.Discard).Boundary()
+	contentType := mime.FormatMediaType(mediaTypeMultipart, map[string]string{"boundary": boundary})
+
+	return &requestBody{
+		contentType: contentType,
+		open: func() (io.Reader, int64, error) {
+			reader, pipe := io.Pipe()
+			go func() {
+				writer := multipart.NewWriter(pipe)
+				err := writer.SetBoundary(boundary)
+				if err == nil {
+					err = writeMultipartFields(writer, media, fields)
+				}
+				if err == nil {
+					err = writer.Close()
+				}
+				pipe.CloseWithError(err)
+			}()
+			return reader, -1, nil
+		},
+	}, nil
+}
+
+func hasFileParts(fields map[string]any) bool {
+	for _, value := range fields {
+		if _, ok := value.(*binaryData); ok {
+			return true
+		}
+		if items, ok := value.([]any); ok {
+			for _, item := range items {
+				if _, ok := item.(*binaryData); ok {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func writeMultipartFields(writer *multipart.Writer, media *openapi3.MediaType, fields map[string]any) error {
+	for _, name := range sortedKeys(fields) {
+		contentType := ""
+		if encoding := media.Encoding[name]; encoding != nil {
+			// The encoding may list several content types, send the first
+			contentType = strings.TrimSpace(strings.Split(encoding.ContentType, ",")[0])
+		}
+
+		values := []any{fields[name]}
+		if items, ok := fields[name].([]any); ok && !strings.Contains(contentType, "json") {
+			values = items
+		}
+
+		for _, value := range values {
+			partType := contentType
+			var data []byte
+			switch v := value.(type) {
+			case *binaryData:
+				if err := writeFilePart(writer, name, partType, v); err != nil {
+					return err
+				}
+				continue
+			case map[string]any, []any:
+				if partType == "" {
+					partType = "application/json"
+				}
+				encoded, err := json.Marshal(v)
+				if err != nil {
+					return fmt.Errorf("failed to marshal multipart field %s: %w", name, err)
+				}
+				data = encoded
+			default:
+				if strings.Contains(partType, "json") {
+					encoded, err := json.Marshal(v)
+					if err != nil {
+						return fmt.Errorf("failed to marshal multipart field %s: %w", name, err)
+					}
+					data = encoded
+				} else {
+					data = []byte(formatScalar(v))
+				}
+			}
+
+			if err := writePart(writer, name, "", partType, bytes.NewReader(data)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeFilePart streams file content into a form-data part
+func writeFilePart(writer *multipart.Writer, name, contentType string, data *binaryData) error {
+	if contentType == "" {
+		contentType = data.contentType
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	filename := data.filename
+	if filename == "" {
+		filename = name
+	}
+
+	reader, _, err := data.open()
+	if err != nil {
+		return err
+	}
+	if closer, ok := reader.(io.Closer); ok {
+		//nolint
+		defer closer.Close()
+	}
+	return writePart(writer, name, filename, contentType, reader)
+}
+
+// writePart writes a form-data part, leaving out the Content-Type header of
+// plain text parts
+func writePart(writer *multipart.Writer, name, filename, contentType string, content io.Reader) error {
+	disposition := map[string]string{"name": name}
+	if filename != "" {
+		disposition["filename"] = filename
+	}
+
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", mime.FormatMediaType("form-data", disposition))
+	if contentType != "" && contentType != "text/plain" {
+		header.Set("Content-Type", contentType)
+	}
+
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return fmt.Errorf("failed to create multipart field %s: %w", name, err)
+	}
+	if _, err := io.Copy(part, content); err != nil {
+		return fmt.Errorf("failed to write multipart field %s: %w", name, err)
+	}
+	return nil
+}
+
+// closeBody closes a request body that will not be sent
+func closeBody(body io.Reader) {
+	if closer, ok := body.(io.Closer); ok {
+		//nolint
+		closer.Close()
+	}
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,250 @@
+package openapimcp
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func bodyOperation(content openapi3.Content) *openapi3.Operation {
+	return &openapi3.Operation{RequestBody: &openapi3.RequestBodyRef{Value: &openapi3.RequestBody{Content: content}}}
+}
+
+func readRequestBody(t *testing.T, body *requestBody) []byte {
+	t.Helper()
+
+	reader, _, err := body.open()
+	require.NoError(t, err)
+	defer closeBody(reader)
+
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	return data
+}
+
+func TestRequestBodyMedia(t *testing.T) {
+	schema := openapi3.NewObjectSchema().NewRef()
+	tests := []struct {
+		name     string
+		content  openapi3.Content
+		expected string
+	}{
+		{"json preferred", openapi3.Content{
+			mediaTypeMultipart:     {Schema: schema},
+			mediaTypeForm:          {Schema: schema},
+			"application/json":     {Schema: schema},
+			"application/hal+json": {Schema: schema},
+		}, "application/json"},
+		{"json suffix", openapi3.Content{"application/merge-patch+json": {Schema: schema}, mediaTypeForm: {Schema: schema}}, "application/merge-patch+json"},
+		{"form over multipart", openapi3.Content{mediaTypeMultipart: {Schema: schema}, mediaTypeForm: {Schema: schema}}, mediaTypeForm},
+		{"multipart", openapi3.Content{mediaTypeMultipart: {Schema: schema}, "application/xml": {Schema: schema}}, mediaTypeMultipart},
+		{"unsupported", openapi3.Content{"text/plain": {Schema: schema}}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mediaType, _ := requestBodyMedia(bodyOperation(tt.content))
+			assert.Equal(t, tt.expected, mediaType)
+		})
+	}
+}
+
+func TestEncodeRequestBody_Form(t *testing.T) {
+	explode := false
+	media := &openapi3.MediaType{Encoding: map[string]*openapi3.Encoding{
+		"scopes":   {Style: "spaceDelimited", Explode: &explode},
+		"metadata": {ContentType: "application/json"},
+	}}
+
+	body, err := encodeRequestBody(mediaTypeForm, media, map[string]any{
+		"grant_type": "password",
+		"username":   "jane doe",
+		"scopes":     []any{"read", "write"},
+		"ids":        []any{float64(1), float64(2)},
+		"metadata":   map[string]any{"a": "b"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, mediaTypeForm, body.contentType)
+	assert.Equal(t, "grant_type=password&ids=1&ids=2&metadata=%7B%22a%22%3A%22b%22%7D&scopes=read%20write&username=jane+doe", string(readRequestBody(t, body)))
+}
+
+func TestEncodeRequestBody_Multipart(t *testing.T) {
+	media := &openapi3.MediaType{Encoding: map[string]*openapi3.Encoding{
+		"avatar": {ContentType: "image/png, image/jpeg"},
+	}}
+
+	body, err := encodeRequestBody(mediaTypeMultipart, media, map[string]any{
+		"name":    "Rex",
+		"tags":    []any{"a", "b"},
+		"address": map[string]any{"city": "Oslo"},
+		"avatar":  "PNGDATA",
+	})
+	require.NoError(t, err)
+
+	mediaType, params, err := mime.ParseMediaType(body.contentType)
+	require.NoError(t, err)
+	assert.Equal(t, mediaTypeMultipart, mediaType)
+
+	type part struct{ name, contentType, data string }
+	parts := []part{}
+	reader := multipart.NewReader(bytes.NewReader(readRequestBody(t, body)), params["boundary"])
+	for {
+		p, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		data, err := io.ReadAll(p)
+		require.NoError(t, err)
+		parts = append(parts, part{p.FormName(), p.Header.Get("Content-Type"), string(data)})
+	}
+
+	assert.Equal(t, []part{
+		{"address", "application/json", `{"city":"Oslo"}`},
+		{"avatar", "image/png", "PNGDATA"},
+		{"name", "", "Rex"},
+		{"tags", "", "a"},
+		{"tags", "", "b"},
+	}, parts)
+}
+
+func TestBuildMCPServerFromSpec_FormRequestBody(t *testing.T) {
+	var gotContentType string
+	var gotForm url.Values
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotContentType = r.Header.Get("Content-Type")
+		require.NoError(t, r.ParseForm())
+		gotForm = r.PostForm
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer backend.Close()
+
+	schema := openapi3.NewObjectSchema().
+		WithProperty("username", openapi3.NewStringSchema()).
+		WithProperty("password", openapi3.NewStringSchema())
+	schema.Required = []string{"username", "password"}
+	op := bodyOperation(openapi3.Content{mediaTypeForm: {Schema: schema.NewRef()}})
+	op.OperationID = "login"
+	op.Responses = openapi3.NewResponses()
+	spec := &openapi3.T{Paths: openapi3.NewPaths(openapi3.WithPath("/token", &openapi3.PathItem{Post: op}))}
+
+	mcpServer, err := BuildMCPServerFromSpec(spec, &APIConfig{BaseURL: backend.URL})
+	require.NoError(t, err)
+
+	tools := listTools(t, mcpServer)
+	require.Len(t, tools, 1)
+	assert.ElementsMatch(t, []string{"username", "password"}, tools[0].InputSchema.Required)
+
+	result := callTool(t, mcpServer, "login", map[string]any{"username": "jane", "password": "s3cr&t"})
+	assert.False(t, result.IsError)
+	assert.Equal(t, mediaTypeForm, gotContentType)
+	assert.Equal(t, url.Values{"username": {"jane"}, "password": {"s3cr&t"}}, gotForm)
+}
+
+func TestFlattenBody(t *testing.T) {
+	mapSchema := openapi3.NewObjectSchema().WithProperty("name", openapi3.NewStringSchema())
+	mapSchema.AdditionalProperties = openapi3.AdditionalProperties{Schema: openapi3.NewStringSchema().NewRef()}
+
+	tests := []struct {
+		name     string
+		schema   *openapi3.Schema
+		expected bool
+	}{
+		{"object with properties", openapi3.NewObjectSchema().WithProperty("name", openapi3.NewStringSchema()), true},
+		{"untyped object with properties", &openapi3.Schema{Properties: openapi3.Schemas{"name": openapi3.NewStringSchema().NewRef()}}, true},
+		{"array", openapi3.NewArraySchema().WithItems(openapi3.NewStringSchema()), false},
+		{"primitive", openapi3.NewStringSchema(), false},
+		{"oneOf", openapi3.NewOneOfSchema(openapi3.NewStringSchema(), openapi3.NewIntegerSchema()), false},
+		{"map", mapSchema, false},
+		{"free-form object", openapi3.NewObjectSchema(), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, flattenBody(tt.schema.NewRef()))
+		})
+	}
+}
+
+func TestBuildMCPServerFromSpec_WholeBodyArgument(t *testing.T) {
+	var gotBody string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		gotBody = string(data)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer backend.Close()
+
+	pet := openapi3.NewObjectSchema().WithProperty("name", openapi3.NewStringSchema())
+	pet.Required = []string{"name"}
+	labels := openapi3.NewObjectSchema()
+	labels.AdditionalProperties = openapi3.AdditionalProperties{Schema: openapi3.NewStringSchema().NewRef()}
+
+	jsonOperation := func(operationID string, schema *openapi3.Schema) *openapi3.Operation {
+		return &openapi3.Operation{
+			OperationID: operationID,
+			RequestBody: &openapi3.RequestBodyRef{Value: openapi3.NewRequestBody().WithRequired(true).WithJSONSchema(schema)},
+			Responses:   openapi3.NewResponses(),
+		}
+	}
+	spec := &openapi3.T{Paths: openapi3.NewPaths(
+		openapi3.WithPath("/pets", &openapi3.PathItem{Post: jsonOperation("createPets", openapi3.NewArraySchema().WithItems(pet))}),
+		openapi3.WithPath("/labels", &openapi3.PathItem{Put: jsonOperation("setLabels", labels)}),
+	)}
+
+	mcpServer, err := BuildMCPServerFromSpec(spec, &APIConfig{BaseURL: backend.URL})
+	require.NoError(t, err)
+
+	for _, tool := range listTools(t, mcpServer) {
+		assert.Equal(t, []string{"body"}, tool.InputSchema.Required, tool.Name)
+		assert.Len(t, tool.InputSchema.Properties, 1, tool.Name)
+		if tool.Name == "setLabels" {
+			body := tool.InputSchema.Properties["body"].(map[string]any)
+			assert.Equal(t, map[string]any{"type": "string"}, body["additionalProperties"])
+		}
+	}
+
+	result := callTool(t, mcpServer, "createPets", map[string]any{"body": []any{map[string]any{"name": "Rex"}, map[string]any{"name": "Tom"}}})
+	require.False(t, result.IsError, result.Content)
+	assert.JSONEq(t, `[{"name":"Rex"},{"name":"Tom"}]`, gotBody)
+
+	result = callTool(t, mcpServer, "createPets", map[string]any{"body": []any{map[string]any{}}})
+	assert.True(t, result.IsError)
+
+	result = callTool(t, mcpServer, "setLabels", map[string]any{"body": map[string]any{"env": "prod", "team": "core"}})
+	require.False(t, result.IsError, result.Content)
+	assert.JSONEq(t, `{"env":"prod","team":"core"}`, gotBody)
+}
+
+func TestCreateHandler_BodyEncodingFailure(t *testing.T) {
+	schema := openapi3.NewObjectSchema().WithProperty("metadata", &openapi3.Schema{})
+	op := &openapi3.Operation{
+		OperationID: "createPet",
+		RequestBody: &openapi3.RequestBodyRef{Value: openapi3.NewRequestBody().WithJSONSchema(schema)},
+		Responses:   openapi3.NewResponses(),
+	}
+
+	// Arguments decoded from JSON always marshal, call the handler directly
+	// with a value that passes validation but cannot be encoded
+	handler := NewMCPServerBuilder(&APIConfig{}).createHandler(http.MethodPost, "http://localhost/pets", op)
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{"metadata": make(chan int)}
+
+	result, err := handler(context.Background(), request)
+	require.NoError(t, err)
+	require.True(t, result.IsError)
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "Invalid arguments")
+	assert.Contains(t, text, "failed to marshal request body")
+}
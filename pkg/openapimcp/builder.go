@@ -1,31 +1,68 @@
 package openapimcp
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
-	"net/url"
-	"regexp"
+	"sort"
 	"strings"
+	"text/template"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	log "github.com/sirupsen/logrus"
 )
 
 // APIConfig holds configuration for API calls
 type APIConfig struct {
-	BaseURL    string
+	BaseURL    string // Overrides the servers declared in the spec when set
 	HTTPClient *http.Client
 	Headers    map[string]string
+
+	SpecURL         string            // URL the spec was loaded from, used to resolve relative server URLs
+	ServerIndex     int               // Index of the spec-level server to use
+	ServerVariables map[string]string // Overrides for server variable defaults
+
+	Credentials map[string]Credentials // Credentials for the spec's security schemes, keyed by scheme name
+
+	Filter OperationFilter // Selects the operations exposed as tools
+
+	ValidateResponses bool     // Report API responses that do not match the spec
+	SessionCookies    bool     // Keep cookies set by the API in a jar per MCP session
+	FileRoots         []string // Directories file:// arguments of binary fields may be read from
+
+	ToolNaming     ToolNaming            // How tool names are derived from operations, defaults to OperationIDNaming
+	BodyCollisions BodyCollisionStrategy // How body fields named like a parameter are exposed, defaults to PrefixBodyFields
+
+	DescriptionTemplate  string // text/template for tool descriptions, executed with ToolDescriptionData
+	DescriptionMaxLength int    // Longest tool description in characters, zero means no limit
+}
+
+// toolMethods are the methods operations are exposed as tools for, in the
+// order of the OpenAPI Path Item Object
+var toolMethods = []string{
+	http.MethodGet,
+	http.MethodPut,
+	http.MethodPost,
+	http.MethodDelete,
+	http.MethodOptions,
+	http.MethodHead,
+	http.MethodPatch,
 }
 
 // MCPServerBuilder builds MCP servers from OpenAPI specs
 type MCPServerBuilder struct {
 	config *APIConfig
+
+	securitySchemes openapi3.SecuritySchemes
+	defaultSecurity openapi3.SecurityRequirements
+	tokenSources    map[string]*oauth2TokenSource
+
+	cookieJars          *sessionJars
+	descriptionTemplate *template.Template
 }
 
 // NewMCPServerBuilder creates a new builder with configuration
@@ -39,39 +76,60 @@ func NewMCPServerBuilder(config *APIConfig) *MCPServerBuilder {
 // BuildMCPServerFromSpec creates an MCP server from an OpenAPI spec
 func (b *MCPServerBuilder) BuildMCPServerFromSpec(spec *openapi3.T) (*server.MCPServer, error) {
 	mcpServer := server.NewMCPServer("openapi-server", "1.0.0")
+	if b.config.SessionCookies {
+		b.cookieJars = newSessionJars()
+	}
 
-	// Extract base URL from spec if not provided in config
-	baseURL := b.config.BaseURL
-	if baseURL == "" && len(spec.Servers) > 0 {
-		baseURL = spec.Servers[0].URL
+	matcher, err := newOperationMatcher(b.config.Filter)
+	if err != nil {
+		return nil, err
 	}
 
-	// Process all paths and operations
-	for path, pathItem := range spec.Paths.Map() {
-		operations := map[string]*openapi3.Operation{
-			"GET":     pathItem.Get,
-			"POST":    pathItem.Post,
-			"PUT":     pathItem.Put,
-			"DELETE":  pathItem.Delete,
-			"PATCH":   pathItem.Patch,
-			"HEAD":    pathItem.Head,
-			"OPTIONS": pathItem.Options,
-		}
+	if b.descriptionTemplate, err = parseDescriptionTemplate(b.config.DescriptionTemplate); err != nil {
+		return nil, err
+	}
 
-		for method, operation := range operations {
-			if operation == nil {
+	if spec.Components != nil {
+		b.securitySchemes = spec.Components.SecuritySchemes
+	}
+	b.defaultSecurity = spec.Security
+	b.initSecuritySchemes()
+
+	// Collect the operations first, tool names must be unique across the spec.
+	// Paths and methods are walked in a fixed order so that tools are
+	// registered identically on every run.
+	operations := []toolOperation{}
+	for _, path := range sortedPaths(spec.Paths) {
+		pathItem := spec.Paths.Value(path)
+		for _, method := range toolMethods {
+			operation := pathItem.GetOperation(method)
+			if operation == nil || isHidden(operation.Extensions) || !matcher.matches(method, path, operation) {
 				continue
 			}
 
-			toolName := generateToolName(method, path, operation)
-			tool := b.createTool(toolName, operation)
+			// Path-level parameters apply to every operation unless overridden
+			effectiveOp := *operation
+			effectiveOp.Parameters = mergeParameters(pathItem.Parameters, operation.Parameters)
 
-			// Create handler for this specific endpoint
-			handler := b.createHandler(method, baseURL+path, operation)
+			operations = append(operations, toolOperation{method: method, path: path, pathItem: pathItem, op: &effectiveOp})
+		}
+	}
 
-			// Register tool with handler
-			mcpServer.AddTool(tool, handler)
+	names := b.toolNames(operations)
+	for i, operation := range operations {
+		baseURL, err := b.resolveBaseURL(spec, operation.pathItem, operation.op)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve server URL for %s %s: %w", operation.method, operation.path, err)
 		}
+
+		tool := b.createTool(names[i], operation.op)
+		tool.Annotations = toolAnnotations(operation.method, operation.op)
+
+		// Create handler for this specific endpoint
+		handler := b.createHandler(operation.method, baseURL+operation.path, operation.op)
+
+		// Register tool with handler
+		mcpServer.AddTool(tool, handler)
 	}
 
 	return mcpServer, nil
@@ -84,115 +142,184 @@ func (b *MCPServerBuilder) createTool(toolName string, op *openapi3.Operation) m
 	// Parameters (path/query/header)
 	for _, paramRef := range op.Parameters {
 		param := paramRef.Value
-		if param == nil {
+		if param == nil || isHidden(param.Extensions) {
 			continue
 		}
 
+		// The whole schema is kept so that items, bounds, patterns and defaults reach the caller
 		schemaMap := map[string]any{}
-		if param.Schema != nil {
-			paramSchema := convertSchemaToMCP(param.Schema)
-
-			if t, ok := paramSchema["type"]; ok {
-				schemaMap["type"] = t
-			}
-			if f, ok := paramSchema["format"]; ok {
-				schemaMap["format"] = f
-			}
-			if e, ok := paramSchema["enum"]; ok {
-				schemaMap["enum"] = e
-			}
-			if ex, ok := paramSchema["example"]; ok {
-				schemaMap["example"] = ex
-			}
+		if schemaRef := parameterSchema(param); schemaRef != nil {
+			schemaMap = convertSchemaToMCP(schemaRef)
 		}
 		if param.Description != "" {
 			schemaMap["description"] = param.Description
 		}
+		if param.Example != nil {
+			schemaMap["example"] = param.Example
+		}
+		if examples := parameterExampleValues(param); len(examples) > 0 {
+			schemaMap["examples"] = examples
+		}
+		if param.Deprecated {
+			schemaMap["deprecated"] = true
+		}
+		if def, ok := extensionDefault(param.Extensions); ok {
+			schemaMap["default"] = def
+		}
 		properties[param.Name] = schemaMap
 
-		if param.Required {
+		// A parameter with an x-mcp-default can always be omitted by the caller
+		if _, hasDefault := extensionDefault(param.Extensions); param.Required && !hasDefault {
 			requiredSet[param.Name] = struct{}{}
 		}
 	}
 
-	// Request body (JSON only)
-	if op.RequestBody != nil && op.RequestBody.Value != nil {
-		for mediaType, mediaTypeObj := range op.RequestBody.Value.Content {
-			if strings.Contains(mediaType, "json") && mediaTypeObj.Schema != nil {
-				bodySchema := convertSchemaToMCP(mediaTypeObj.Schema)
+	// Request body (JSON, form-urlencoded, multipart or binary)
+	mediaType, media := requestBodyMedia(op)
+	switch {
+	case media == nil:
+	case isBinaryMedia(mediaType, media):
+		properties[bodyArgumentName(op)] = map[string]any{
+			"type":        "string",
+			"description": binaryDescription,
+		}
+		if op.RequestBody.Value.Required {
+			requiredSet[bodyArgumentName(op)] = struct{}{}
+		}
+	case !flattenBody(media.Schema):
+		// Bodies that cannot be flattened losslessly are passed as a whole
+		properties[bodyArgumentName(op)] = convertSchemaToMCP(media.Schema)
+		if op.RequestBody.Value.Required {
+			requiredSet[bodyArgumentName(op)] = struct{}{}
+		}
+	default:
+		bodySchema := convertSchemaToMCP(media.Schema)
+		fieldArguments := b.bodyFieldArguments(op, media.Schema)
+
+		// Body fields colliding with a parameter are renamed or nested under a body object
+		nestedName := ""
+		nestedProps := map[string]any{}
+		nestedRequired := []string{}
+
+		if props, ok := bodySchema["properties"].(map[string]any); ok {
+			describeBinaryProperties(media.Schema, props)
+			for k, v := range props {
+				path := fieldArguments[k]
+				if len(path) > 1 {
+					nestedName = path[0]
+					nestedProps[path[1]] = v
+					continue
+				}
+				properties[path[0]] = v
+			}
+		}
 
-				if props, ok := bodySchema["properties"].(map[string]any); ok {
-					for k, v := range props {
-						properties[k] = v
-					}
+		bodyRequired := []string{}
+		if required, ok := bodySchema["required"].([]string); ok {
+			bodyRequired = required
+		} else if required, ok := bodySchema["required"].([]any); ok {
+			for _, r := range required {
+				if s, ok := r.(string); ok {
+					bodyRequired = append(bodyRequired, s)
 				}
-				if required, ok := bodySchema["required"].([]string); ok {
-					for _, r := range required {
-						requiredSet[r] = struct{}{}
-					}
-				} else if required, ok := bodySchema["required"].([]any); ok {
-					for _, r := range required {
-						if s, ok := r.(string); ok {
-							requiredSet[s] = struct{}{}
-						}
-					}
+			}
+		}
+		for _, r := range bodyRequired {
+			// A body field with an x-mcp-default can always be omitted by the caller, like a parameter
+			if prop := media.Schema.Value.Properties[r]; prop != nil && prop.Value != nil {
+				if _, hasDefault := extensionDefault(prop.Value.Extensions); hasDefault {
+					continue
 				}
-				break
 			}
+			switch path := fieldArguments[r]; {
+			case len(path) > 1:
+				nestedRequired = append(nestedRequired, path[1])
+			case len(path) == 1:
+				requiredSet[path[0]] = struct{}{}
+			default:
+				requiredSet[r] = struct{}{}
+			}
+		}
+
+		if len(nestedProps) > 0 {
+			nested := map[string]any{
+				"type":        "object",
+				"description": "Request body fields named like a parameter of the operation",
+				"properties":  nestedProps,
+			}
+			if len(nestedRequired) > 0 {
+				nested["required"] = nestedRequired
+				requiredSet[nestedName] = struct{}{}
+			}
+			properties[nestedName] = nested
 		}
 	}
 
-	// Build required slice
+	// Build required slice, sorted so that the schema is stable across runs
 	required := []string{}
 	for k := range requiredSet {
 		required = append(required, k)
 	}
+	sort.Strings(required)
 
 	// Return valid OpenAI-compatible tool schema
-	return mcp.Tool{
+	tool := mcp.Tool{
 		Name:        toolName,
-		Description: op.Description,
+		Description: b.toolDescription(op),
 		InputSchema: mcp.ToolInputSchema{
 			Type:       "object",
 			Properties: properties,
 			Required:   required,
 		},
 	}
+
+	// Publish the success response schema so callers can rely on structured results
+	if output := newToolOutput(op); output != nil {
+		if outputSchema, err := json.Marshal(output.schema); err == nil {
+			tool.RawOutputSchema = outputSchema
+		}
+	}
+
+	return tool
 }
 
 // createHandler creates a handler function for an API endpoint
 func (b *MCPServerBuilder) createHandler(method, fullURL string, op *openapi3.Operation) server.ToolHandlerFunc {
+	output := newToolOutput(op)
+	bodyName := bodyArgumentName(op)
+	var fieldArguments map[string][]string
+	if _, media := requestBodyMedia(op); media != nil {
+		fieldArguments = b.bodyFieldArguments(op, media.Schema)
+	}
+
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		args := request.GetArguments()
 		finalURL := fullURL
-		queryParams := url.Values{}
-		headers := http.Header{}
+		queryParams := []string{}
 		bodyFields := map[string]any{}
+		violations := []string{}
 
-		// Handle OpenAPI parameters (path, query, header)
+		// Handle OpenAPI path and query parameters, headers and cookies are set on the request
 		for _, paramRef := range op.Parameters {
 			param := paramRef.Value
 			if param == nil {
 				continue
 			}
 
-			value, exists := args[param.Name]
-			if !exists && param.Required {
-				return nil, fmt.Errorf("required parameter %s is missing", param.Name)
+			value, exists := argumentValue(args, param.Name, param.Extensions)
+			if !exists && param.Required && !isHidden(param.Extensions) {
+				violations = append(violations, fmt.Sprintf("%s: required parameter is missing", param.Name))
 			}
 			if !exists {
 				continue
 			}
-
-			valueStr := fmt.Sprintf("%v", value)
+			violations = append(violations, validateParameter(param, value)...)
 
 			switch param.In {
 			case "path":
-				finalURL = strings.ReplaceAll(finalURL, "{"+param.Name+"}", valueStr)
+				finalURL = strings.ReplaceAll(finalURL, "{"+param.Name+"}", serializePathParameter(param, value))
 			case "query":
-				queryParams.Add(param.Name, valueStr)
-			case "header":
-				headers.Add(param.Name, valueStr)
+				queryParams = append(queryParams, serializeQueryParameter(param, value)...)
 			}
 		}
 
@@ -202,34 +329,98 @@ func (b *MCPServerBuilder) createHandler(method, fullURL string, op *openapi3.Op
 			if strings.Contains(finalURL, "?") {
 				sep = "&"
 			}
-			finalURL += sep + queryParams.Encode()
+			finalURL += sep + strings.Join(queryParams, "&")
 		}
 
 		// Reconstruct request body by excluding known path/query/header params
-		if op.RequestBody != nil && op.RequestBody.Value != nil {
-			for mediaType, media := range op.RequestBody.Value.Content {
-				if strings.Contains(mediaType, "json") && media.Schema != nil {
-					if schemaRef := media.Schema; schemaRef.Value != nil {
-						for propName := range schemaRef.Value.Properties {
-							if val, exists := args[propName]; exists {
-								bodyFields[propName] = val
-							} else if contains(schemaRef.Value.Required, propName) {
-								return nil, fmt.Errorf("missing required request body field: %s", propName)
-							}
-						}
-					}
-					break
+		var body *requestBody
+		mediaType, media := requestBodyMedia(op)
+		switch {
+		case media == nil:
+		case isBinaryMedia(mediaType, media):
+			// Binary bodies are passed as a whole in the body argument
+			value, exists := args[bodyName]
+			if !exists {
+				if op.RequestBody.Value.Required {
+					violations = append(violations, fmt.Sprintf("%s: required field is missing", bodyName))
+				}
+				break
+			}
+			data, err := b.resolveBinary(value)
+			if err != nil {
+				violations = append(violations, formatViolation([]string{bodyName}, err.Error()))
+				break
+			}
+			body = binaryRequestBody(mediaType, data)
+		case !flattenBody(media.Schema):
+			// The body is passed as a whole in the body argument
+			value, exists := args[bodyName]
+			if !exists {
+				if op.RequestBody.Value.Required {
+					violations = append(violations, fmt.Sprintf("%s: required field is missing", bodyName))
+				}
+				break
+			}
+			if media.Schema.Value != nil {
+				err := media.Schema.Value.VisitJSON(value, openapi3.MultiErrors(), openapi3.VisitAsRequest())
+				violations = append(violations, schemaViolations([]string{bodyName}, err)...)
+			}
+			if len(violations) == 0 {
+				var err error
+				if body, err = encodeRequestBody(mediaType, media, value); err != nil {
+					violations = append(violations, formatViolation([]string{bodyName}, err.Error()))
+				}
+			}
+		default:
+			schemaRef := media.Schema
+			for propName, propSchema := range schemaRef.Value.Properties {
+				var propExtensions map[string]any
+				if propSchema != nil && propSchema.Value != nil {
+					propExtensions = propSchema.Value.Extensions
+				}
+
+				// Colliding fields are read from their renamed or nested argument
+				container, name := argumentContainer(args, fieldArguments[propName])
+				if val, exists := argumentValue(container, name, propExtensions); exists {
+					bodyFields[propName] = val
+				}
+			}
+
+			bodyViolations := renameViolations(validateBody(schemaRef, bodyFields), fieldArguments)
+			if len(bodyViolations) == 0 {
+				bodyViolations = b.resolveBinaryFields(mediaType, media, bodyFields)
+			}
+			violations = append(violations, bodyViolations...)
+
+			if len(violations) == 0 {
+				var err error
+				if body, err = encodeRequestBody(mediaType, media, bodyFields); err != nil {
+					// The fields are top-level arguments, there is no body argument to point at
+					violations = append(violations, formatViolation(nil, err.Error()))
 				}
 			}
 		}
 
+		// Report every violation at once rather than letting the API reject the request
+		if len(violations) > 0 {
+			return invalidArgumentsResult(violations), nil
+		}
+
 		// Make request
-		resp, err := b.makeHTTPRequest(ctx, method, finalURL, bodyFields, op, args)
+		resp, err := b.makeHTTPRequest(ctx, method, finalURL, body, op, args)
 		if err != nil {
 			return nil, fmt.Errorf("API request failed: %w", err)
 		}
 
-		return mcp.NewToolResultText(resp), nil
+		result := output.toolResult(op, resp)
+		if b.config.ValidateResponses {
+			if warnings := validateResponse(op, resp); len(warnings) > 0 {
+				log.Warnf("Response of %s %s does not match the spec:\n- %s", method, fullURL, strings.Join(warnings, "\n- "))
+				result = withResponseWarnings(result, warnings)
+			}
+		}
+
+		return result, nil
 	}
 }
 
@@ -243,25 +434,76 @@ func contains(list []string, target string) bool {
 }
 
 // makeHTTPRequest performs the actual HTTP request
-func (b *MCPServerBuilder) makeHTTPRequest(ctx context.Context, method, url string, body any, op *openapi3.Operation, args map[string]any) (string, error) {
-	var bodyReader io.Reader
+func (b *MCPServerBuilder) makeHTTPRequest(ctx context.Context, method, url string, body *requestBody, op *openapi3.Operation, args map[string]any) (*apiResponse, error) {
+	req, tokens, err := b.newRequest(ctx, method, url, body, op, args)
+	if err != nil {
+		return nil, err
+	}
 
-	if body != nil {
-		bodyBytes, err := json.Marshal(body)
+	client := b.httpClient(ctx)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request failed: %w", err)
+	}
+
+	// A rejected OAuth2 token may have been revoked before its expiry, retry once with a fresh one
+	if resp.StatusCode == http.StatusUnauthorized && len(tokens) > 0 {
+		//nolint
+		resp.Body.Close()
+		for _, token := range tokens {
+			token.source.invalidate(token.scopes)
+		}
+
+		req, _, err = b.newRequest(ctx, method, url, body, op, args)
+		if err != nil {
+			return nil, err
+		}
+		resp, err = client.Do(req)
 		if err != nil {
-			return "", fmt.Errorf("failed to marshal request body: %w", err)
+			return nil, fmt.Errorf("HTTP request failed: %w", err)
+		}
+	}
+
+	//nolint
+	defer resp.Body.Close()
+
+	responseBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	return &apiResponse{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Header:     resp.Header,
+		Body:       responseBody,
+	}, nil
+}
+
+// newRequest builds an authenticated request for the operation, returning the
+// OAuth2 tokens it carries
+func (b *MCPServerBuilder) newRequest(ctx context.Context, method, url string, body *requestBody, op *openapi3.Operation, args map[string]any) (*http.Request, []appliedToken, error) {
+	var bodyReader io.Reader
+	bodyLength := int64(0)
+	if body != nil {
+		var err error
+		if bodyReader, bodyLength, err = body.open(); err != nil {
+			return nil, nil, err
 		}
-		bodyReader = bytes.NewReader(bodyBytes)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		closeBody(bodyReader)
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Set default headers
-	if bodyReader != nil {
-		req.Header.Set("Content-Type", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", body.contentType)
+		if bodyLength > 0 {
+			req.ContentLength = bodyLength
+		}
 	}
 
 	// Set configured headers
@@ -269,75 +511,86 @@ func (b *MCPServerBuilder) makeHTTPRequest(ctx context.Context, method, url stri
 		req.Header.Set(key, value)
 	}
 
-	// Set parameter headers
+	// Authenticate according to the operation's security requirements
+	tokens, err := b.applySecurity(req, op)
+	if err != nil {
+		closeBody(req.Body)
+		return nil, nil, err
+	}
+
+	// Set parameter headers and cookies
 	for _, paramRef := range op.Parameters {
 		param := paramRef.Value
-		if param == nil || param.In != "header" {
+		if param == nil {
 			continue
 		}
 
-		if value, exists := args[param.Name]; exists {
-			req.Header.Set(param.Name, fmt.Sprintf("%v", value))
+		value, exists := argumentValue(args, param.Name, param.Extensions)
+		if !exists {
+			continue
 		}
-	}
 
-	resp, err := b.config.HTTPClient.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("HTTP request failed: %w", err)
-	}
-
-	//nolint
-	defer resp.Body.Close()
-
-	responseBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read response body: %w", err)
-	}
-
-	// Format response with status information
-	result := fmt.Sprintf("Status: %d %s\n", resp.StatusCode, resp.Status)
-
-	if len(responseBody) > 0 {
-		// Try to pretty-print JSON
-		var jsonObj any
-		if err := json.Unmarshal(responseBody, &jsonObj); err == nil {
-			if prettyJSON, err := json.MarshalIndent(jsonObj, "", "  "); err == nil {
-				result += "Response:\n" + string(prettyJSON)
-			} else {
-				result += "Response:\n" + string(responseBody)
-			}
-		} else {
-			result += "Response:\n" + string(responseBody)
+		switch param.In {
+		case "header":
+			req.Header.Set(param.Name, serializeHeaderParameter(param, value))
+		case "cookie":
+			req.AddCookie(&http.Cookie{Name: param.Name, Value: serializeCookieParameter(param, value)})
 		}
 	}
 
-	return result, nil
+	return req, tokens, nil
 }
 
 // Helper functions
 
-// generateToolName creates a unique tool name from method, path, and operation
-func generateToolName(method, path string, op *openapi3.Operation) string {
-	if op.OperationID != "" {
-		return op.OperationID
+// mergeParameters combines path-item and operation parameters. An operation
+// parameter overrides a path-item parameter with the same name and location.
+func mergeParameters(pathParams, opParams openapi3.Parameters) openapi3.Parameters {
+	if len(pathParams) == 0 {
+		return opParams
 	}
 
-	// Clean path for tool name
-	cleanPath := regexp.MustCompile(`[{}]`).ReplaceAllString(path, "")
-	cleanPath = regexp.MustCompile(`[^a-zA-Z0-9_]`).ReplaceAllString(cleanPath, "_")
-	cleanPath = strings.Trim(cleanPath, "_")
+	merged := make(openapi3.Parameters, 0, len(pathParams)+len(opParams))
+	for _, paramRef := range pathParams {
+		if param := paramRef.Value; param != nil && opParams.GetByInAndName(param.In, param.Name) != nil {
+			continue
+		}
+		merged = append(merged, paramRef)
+	}
+	return append(merged, opParams...)
+}
 
-	return strings.ToLower(method) + "_" + cleanPath
+// sortedPaths returns the paths of the spec in lexical order
+func sortedPaths(paths *openapi3.Paths) []string {
+	if paths == nil {
+		return nil
+	}
+	keys := make([]string, 0, paths.Len())
+	for path := range paths.Map() {
+		keys = append(keys, path)
+	}
+	sort.Strings(keys)
+	return keys
 }
 
 // convertSchemaToMCP converts OpenAPI schema to MCP tool schema format
 func convertSchemaToMCP(schemaRef *openapi3.SchemaRef) map[string]any {
-	return convertSchemaToMCPWithRefs(schemaRef, make(map[string]bool))
+	return convertSchemaToMCPWithRefs(schemaRef, make(map[string]bool), false)
+}
+
+// convertResponseSchemaToMCP converts a response schema to an MCP output
+// schema. Unlike arguments, which are passed as strings when their schema
+// has no type, untyped response schemas stay untyped and accept any value.
+func convertResponseSchemaToMCP(schemaRef *openapi3.SchemaRef) map[string]any {
+	return convertSchemaToMCPWithRefs(schemaRef, make(map[string]bool), true)
 }
 
 // convertSchemaToMCPWithRefs converts OpenAPI schema to MCP tool schema format with reference tracking
-func convertSchemaToMCPWithRefs(schemaRef *openapi3.SchemaRef, visited map[string]bool) map[string]any {
+func convertSchemaToMCPWithRefs(schemaRef *openapi3.SchemaRef, visited map[string]bool, response bool) map[string]any {
 	if schemaRef == nil {
+		if response {
+			return map[string]any{}
+		}
 		return map[string]any{"type": "string"}
 	}
 
@@ -358,7 +611,7 @@ func convertSchemaToMCPWithRefs(schemaRef *openapi3.SchemaRef, visited map[strin
 
 		// If we have the resolved schema, process it
 		if schemaRef.Value != nil {
-			result := convertSchemaToMCPWithRefs(&openapi3.SchemaRef{Value: schemaRef.Value}, visited)
+			result := convertSchemaToMCPWithRefs(&openapi3.SchemaRef{Value: schemaRef.Value}, visited, response)
 			// Add reference information
 			refName := extractRefName(schemaRef.Ref)
 			if desc, ok := result["description"].(string); ok {
@@ -377,6 +630,9 @@ func convertSchemaToMCPWithRefs(schemaRef *openapi3.SchemaRef, visited map[strin
 	}
 
 	if schemaRef.Value == nil {
+		if response {
+			return map[string]any{}
+		}
 		return map[string]any{"type": "string"}
 	}
 
@@ -385,17 +641,23 @@ func convertSchemaToMCPWithRefs(schemaRef *openapi3.SchemaRef, visited map[strin
 
 	// Handle type - schema.Type is *openapi3.Types (slice of strings)
 	if schema.Type != nil && len(*schema.Type) > 0 {
-		// Use the first type if multiple types are specified
+		// Use the first type if multiple types are specified, null is added below
 		result["type"] = (*schema.Type)[0]
-	} else {
+		for _, t := range *schema.Type {
+			if t != "null" {
+				result["type"] = t
+				break
+			}
+		}
+	} else if len(schema.Properties) > 0 {
+		result["type"] = "object"
+	} else if len(schema.OneOf) == 0 && len(schema.AnyOf) == 0 && len(schema.AllOf) == 0 && !response {
+		// Compositions carry the types of their subschemas
 		result["type"] = "string"
 	}
 
 	// Get the primary type for comparison
-	primaryType := ""
-	if schema.Type != nil && len(*schema.Type) > 0 {
-		primaryType = (*schema.Type)[0]
-	}
+	primaryType, _ := result["type"].(string)
 
 	// Handle description
 	if schema.Description != "" {
@@ -415,40 +677,56 @@ func convertSchemaToMCPWithRefs(schemaRef *openapi3.SchemaRef, visited map[strin
 	// Handle object properties
 	if primaryType == "object" && len(schema.Properties) > 0 {
 		properties := make(map[string]any)
+		required := []string{}
 		for propName, propSchema := range schema.Properties {
-			properties[propName] = convertSchemaToMCPWithRefs(propSchema, visited)
+			if propSchema != nil && propSchema.Value != nil && isHidden(propSchema.Value.Extensions) {
+				continue
+			}
+			properties[propName] = convertSchemaToMCPWithRefs(propSchema, visited, response)
 		}
 		result["properties"] = properties
 
-		if len(schema.Required) > 0 {
-			result["required"] = schema.Required
+		for _, name := range schema.Required {
+			if _, ok := properties[name]; ok {
+				required = append(required, name)
+			}
+		}
+		if len(required) > 0 {
+			result["required"] = required
 		}
 	}
 
+	// Handle maps
+	if additional := schema.AdditionalProperties; additional.Schema != nil {
+		result["additionalProperties"] = convertSchemaToMCPWithRefs(additional.Schema, visited, response)
+	} else if additional.Has != nil {
+		result["additionalProperties"] = *additional.Has
+	}
+
 	// Handle array items
 	if primaryType == "array" && schema.Items != nil {
-		result["items"] = convertSchemaToMCPWithRefs(schema.Items, visited)
+		result["items"] = convertSchemaToMCPWithRefs(schema.Items, visited, response)
 	}
 
 	// Handle allOf, oneOf, anyOf
 	if len(schema.AllOf) > 0 {
 		result["allOf"] = make([]any, len(schema.AllOf))
 		for i, subSchema := range schema.AllOf {
-			result["allOf"].([]any)[i] = convertSchemaToMCPWithRefs(subSchema, visited)
+			result["allOf"].([]any)[i] = convertSchemaToMCPWithRefs(subSchema, visited, response)
 		}
 	}
 
 	if len(schema.OneOf) > 0 {
 		result["oneOf"] = make([]any, len(schema.OneOf))
 		for i, subSchema := range schema.OneOf {
-			result["oneOf"].([]any)[i] = convertSchemaToMCPWithRefs(subSchema, visited)
+			result["oneOf"].([]any)[i] = convertSchemaToMCPWithRefs(subSchema, visited, response)
 		}
 	}
 
 	if len(schema.AnyOf) > 0 {
 		result["anyOf"] = make([]any, len(schema.AnyOf))
 		for i, subSchema := range schema.AnyOf {
-			result["anyOf"].([]any)[i] = convertSchemaToMCPWithRefs(subSchema, visited)
+			result["anyOf"].([]any)[i] = convertSchemaToMCPWithRefs(subSchema, visited, response)
 		}
 	}
 
@@ -493,12 +771,20 @@ func convertSchemaToMCPWithRefs(schemaRef *openapi3.SchemaRef, visited map[strin
 	if schema.Default != nil {
 		result["default"] = schema.Default
 	}
+	if def, ok := extensionDefault(schema.Extensions); ok {
+		result["default"] = def
+	}
 
 	// Handle examples
 	if schema.Example != nil {
 		result["example"] = schema.Example
 	}
 
+	// Nullable schemas, or 3.1 type lists including null, also accept null
+	if schema.Nullable || schema.Type.Includes("null") {
+		return allowNull(result)
+	}
+
 	return result
 }
 
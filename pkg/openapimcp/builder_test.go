@@ -1,9 +1,16 @@
 package openapimcp
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -374,3 +381,309 @@ func TestCreateTool_EmptyRequiredArray(t *testing.T) {
 	assert.Len(t, tool.InputSchema.Required, 0)
 	assert.Equal(t, []string{}, tool.InputSchema.Required)
 }
+
+// callTool invokes a registered tool through the MCP server's JSON-RPC handler
+func callTool(t *testing.T, mcpServer *server.MCPServer, name string, args map[string]any) *mcp.CallToolResult {
+	t.Helper()
+
+	msg, err := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "tools/call",
+		"params":  map[string]any{"name": name, "arguments": args},
+	})
+	require.NoError(t, err)
+
+	resp := mcpServer.HandleMessage(context.Background(), msg)
+	rpcResp, ok := resp.(mcp.JSONRPCResponse)
+	require.True(t, ok, "unexpected response: %#v", resp)
+	result, ok := rpcResp.Result.(mcp.CallToolResult)
+	require.True(t, ok, "unexpected result: %#v", rpcResp.Result)
+	return &result
+}
+
+// listTools returns the tools registered on the MCP server
+func listTools(t *testing.T, mcpServer *server.MCPServer) []mcp.Tool {
+	t.Helper()
+
+	resp := mcpServer.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+	rpcResp, ok := resp.(mcp.JSONRPCResponse)
+	require.True(t, ok, "unexpected response: %#v", resp)
+	result, ok := rpcResp.Result.(mcp.ListToolsResult)
+	require.True(t, ok, "unexpected result: %#v", rpcResp.Result)
+	return result.Tools
+}
+
+func toolNames(tools []mcp.Tool) []string {
+	names := make([]string, 0, len(tools))
+	for _, tool := range tools {
+		names = append(names, tool.Name)
+	}
+	return names
+}
+
+func TestBuildMCPServerFromSpec_PathItemParameters(t *testing.T) {
+	var gotPath, gotQuery string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotQuery = r.URL.RawQuery
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer backend.Close()
+
+	stringType := openapi3.Types{"string"}
+	pathItem := &openapi3.PathItem{
+		Parameters: openapi3.Parameters{
+			{Value: openapi3.NewPathParameter("id").WithSchema(&openapi3.Schema{Type: &stringType})},
+			{Value: openapi3.NewQueryParameter("fields").WithDescription("path level").WithSchema(&openapi3.Schema{Type: &stringType})},
+		},
+		Get: &openapi3.Operation{
+			OperationID: "get_item",
+			Parameters: openapi3.Parameters{
+				{Value: openapi3.NewQueryParameter("fields").WithDescription("operation level").WithSchema(&openapi3.Schema{Type: &stringType})},
+			},
+			Responses: openapi3.NewResponses(),
+		},
+	}
+	spec := &openapi3.T{Paths: openapi3.NewPaths(openapi3.WithPath("/items/{id}", pathItem))}
+
+	builder := NewMCPServerBuilder(&APIConfig{BaseURL: backend.URL})
+	tool := builder.createTool("get_item", &openapi3.Operation{
+		Parameters: mergeParameters(pathItem.Parameters, pathItem.Get.Parameters),
+	})
+	require.Contains(t, tool.InputSchema.Properties, "id")
+	assert.Contains(t, tool.InputSchema.Required, "id")
+	assert.Equal(t, "operation level", tool.InputSchema.Properties["fields"].(map[string]any)["description"])
+
+	mcpServer, err := builder.BuildMCPServerFromSpec(spec)
+	require.NoError(t, err)
+
+	result := callTool(t, mcpServer, "get_item", map[string]any{"id": "42", "fields": "name"})
+	require.False(t, result.IsError)
+	assert.Equal(t, "/items/42", gotPath)
+	assert.Equal(t, "fields=name", gotQuery)
+}
+
+func TestCreateTool_VendorExtensions(t *testing.T) {
+	builder := &MCPServerBuilder{}
+
+	stringType := openapi3.Types{"string"}
+	objectType := openapi3.Types{"object"}
+
+	op := &openapi3.Operation{
+		OperationID: "listWidgetsV2",
+		Description: "Original description",
+		Extensions: map[string]any{
+			"x-mcp-name":        "list_widgets",
+			"x-mcp-description": "List widgets in the current workspace",
+			"x-mcp-annotations": map[string]any{"title": "List widgets", "readOnlyHint": true},
+		},
+		Parameters: openapi3.Parameters{
+			{Value: &openapi3.Parameter{
+				Name: "tenant", In: "header", Required: true,
+				Schema:     &openapi3.SchemaRef{Value: &openapi3.Schema{Type: &stringType}},
+				Extensions: map[string]any{"x-mcp-hidden": true, "x-mcp-default": "acme"},
+			}},
+			{Value: &openapi3.Parameter{
+				Name: "page_size", In: "query", Required: true,
+				Schema:     &openapi3.SchemaRef{Value: &openapi3.Schema{Type: &stringType}},
+				Extensions: map[string]any{"x-mcp-default": "50"},
+			}},
+		},
+		RequestBody: &openapi3.RequestBodyRef{Value: &openapi3.RequestBody{
+			Content: openapi3.Content{
+				"application/json": {Schema: &openapi3.SchemaRef{Value: &openapi3.Schema{
+					Type: &objectType,
+					Properties: openapi3.Schemas{
+						"name":     {Value: &openapi3.Schema{Type: &stringType}},
+						"internal": {Value: &openapi3.Schema{Type: &stringType, Extensions: map[string]any{"x-mcp-hidden": true}}},
+						"priority": {Value: &openapi3.Schema{Type: &stringType, Extensions: map[string]any{"x-mcp-default": "normal"}}},
+					},
+					Required: []string{"name", "internal", "priority"},
+				}}},
+			},
+		}},
+	}
+
+	toolName := generateToolName("GET", "/widgets", op)
+	assert.Equal(t, "list_widgets", toolName)
+
+	tool := builder.createTool(toolName, op)
+	assert.Equal(t, "List widgets in the current workspace", tool.Description)
+
+	assert.NotContains(t, tool.InputSchema.Properties, "tenant")
+	assert.NotContains(t, tool.InputSchema.Properties, "internal")
+	assert.Equal(t, "50", tool.InputSchema.Properties["page_size"].(map[string]any)["default"])
+	assert.Equal(t, "normal", tool.InputSchema.Properties["priority"].(map[string]any)["default"])
+	// Required parameters and body fields with an x-mcp-default can be omitted
+	assert.Equal(t, []string{"name"}, tool.InputSchema.Required)
+}
+
+func TestBuildMCPServerFromSpec_VendorExtensions(t *testing.T) {
+	var gotTenant, gotQuery string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotTenant = r.Header.Get("tenant")
+		gotQuery = r.URL.RawQuery
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer backend.Close()
+
+	stringType := openapi3.Types{"string"}
+	spec := &openapi3.T{Paths: openapi3.NewPaths(
+		openapi3.WithPath("/widgets", &openapi3.PathItem{
+			Get: &openapi3.Operation{
+				OperationID: "listWidgets",
+				Extensions:  map[string]any{"x-mcp-annotations": map[string]any{"title": "List widgets", "readOnlyHint": true}},
+				Parameters: openapi3.Parameters{
+					{Value: &openapi3.Parameter{
+						Name: "tenant", In: "header", Required: true,
+						Schema:     &openapi3.SchemaRef{Value: &openapi3.Schema{Type: &stringType}},
+						Extensions: map[string]any{"x-mcp-hidden": true, "x-mcp-default": "acme"},
+					}},
+					{Value: &openapi3.Parameter{
+						Name: "page_size", In: "query",
+						Schema:     &openapi3.SchemaRef{Value: &openapi3.Schema{Type: &stringType}},
+						Extensions: map[string]any{"x-mcp-default": "50"},
+					}},
+				},
+				Responses: openapi3.NewResponses(),
+			},
+			Delete: &openapi3.Operation{
+				OperationID: "deleteAllWidgets",
+				Extensions:  map[string]any{"x-mcp-hidden": true},
+				Responses:   openapi3.NewResponses(),
+			},
+		}),
+	)}
+
+	mcpServer, err := BuildMCPServerFromSpec(spec, &APIConfig{BaseURL: backend.URL})
+	require.NoError(t, err)
+
+	// the caller cannot override hidden parameters
+	callTool(t, mcpServer, "listWidgets", map[string]any{"tenant": "other"})
+	assert.Equal(t, "acme", gotTenant)
+	assert.Equal(t, "page_size=50", gotQuery)
+
+	callTool(t, mcpServer, "listWidgets", map[string]any{"page_size": "10"})
+	assert.Equal(t, "page_size=10", gotQuery)
+
+	tools := listTools(t, mcpServer)
+	assert.Equal(t, []string{"listWidgets"}, toolNames(tools))
+
+	// Annotations are computed when tools are registered
+	assert.Equal(t, "List widgets", tools[0].Annotations.Title)
+	require.NotNil(t, tools[0].Annotations.ReadOnlyHint)
+	assert.True(t, *tools[0].Annotations.ReadOnlyHint)
+}
+
+func TestBuildMCPServerFromSpec_Deterministic(t *testing.T) {
+	pet := openapi3.NewObjectSchema().
+		WithProperty("name", openapi3.NewStringSchema()).
+		WithProperty("tag", openapi3.NewStringSchema()).
+		WithProperty("age", openapi3.NewIntegerSchema())
+	pet.Required = []string{"tag", "name", "age"}
+
+	spec := &openapi3.T{Paths: openapi3.NewPaths()}
+	for _, path := range []string{"/pets/{petId}", "/owners/{ownerId}", "/toys/{toyId}", "/stores/{storeId}"} {
+		param := strings.Trim(path[strings.Index(path, "{"):], "{}")
+		spec.Paths.Set(path, &openapi3.PathItem{
+			Parameters: openapi3.Parameters{{Value: openapi3.NewPathParameter(param).WithSchema(openapi3.NewStringSchema())}},
+			Get:        &openapi3.Operation{Responses: openapi3.NewResponses()},
+			Put: &openapi3.Operation{
+				RequestBody: &openapi3.RequestBodyRef{Value: openapi3.NewRequestBody().WithJSONSchema(pet)},
+				Responses:   openapi3.NewResponses(),
+			},
+			Delete: &openapi3.Operation{Responses: openapi3.NewResponses()},
+		})
+	}
+
+	build := func() []byte {
+		mcpServer, err := BuildMCPServerFromSpec(spec, &APIConfig{BaseURL: "http://example.com"})
+		require.NoError(t, err)
+		data, err := json.Marshal(listTools(t, mcpServer))
+		require.NoError(t, err)
+		return data
+	}
+
+	first := build()
+	for i := 0; i < 10; i++ {
+		require.Equal(t, string(first), string(build()))
+	}
+
+	mcpServer, err := BuildMCPServerFromSpec(spec, &APIConfig{BaseURL: "http://example.com"})
+	require.NoError(t, err)
+	for _, tool := range listTools(t, mcpServer) {
+		if tool.Name == "put_pets_petId" {
+			assert.Equal(t, []string{"age", "name", "petId", "tag"}, tool.InputSchema.Required)
+		}
+	}
+}
+
+func TestCreateTool_FullParameterSchema(t *testing.T) {
+	builder := &MCPServerBuilder{}
+
+	limit := openapi3.NewIntegerSchema().WithMin(1).WithMax(100).WithDefault(20)
+	tags := openapi3.NewArraySchema().WithItems(openapi3.NewStringSchema().WithPattern(`^[a-z]+$`))
+	filter := openapi3.NewObjectSchema().WithProperty("color", openapi3.NewStringSchema())
+
+	op := &openapi3.Operation{
+		Parameters: openapi3.Parameters{
+			{Value: openapi3.NewQueryParameter("limit").WithSchema(limit)},
+			{Value: &openapi3.Parameter{
+				Name: "tags", In: "query", Schema: tags.NewRef(), Deprecated: true,
+				Examples: openapi3.Examples{
+					"two": {Value: openapi3.NewExample([]any{"cat", "dog"})},
+					"one": {Value: openapi3.NewExample([]any{"cat"})},
+				},
+			}},
+			{Value: &openapi3.Parameter{
+				Name: "filter", In: "query", Description: "Filter by attributes", Example: map[string]any{"color": "red"},
+				Content: openapi3.NewContentWithJSONSchema(filter),
+			}},
+		},
+	}
+
+	tool := builder.createTool("search", op)
+
+	assert.Equal(t, map[string]any{"type": "integer", "minimum": 1.0, "maximum": 100.0, "default": 20}, tool.InputSchema.Properties["limit"])
+	assert.Equal(t, map[string]any{
+		"type":       "array",
+		"items":      map[string]any{"type": "string", "pattern": `^[a-z]+$`},
+		"examples":   []any{[]any{"cat"}, []any{"cat", "dog"}},
+		"deprecated": true,
+	}, tool.InputSchema.Properties["tags"])
+
+	filterProperty := tool.InputSchema.Properties["filter"].(map[string]any)
+	assert.Equal(t, "object", filterProperty["type"])
+	assert.Equal(t, "Filter by attributes", filterProperty["description"])
+	assert.Equal(t, map[string]any{"color": "red"}, filterProperty["example"])
+	assert.Contains(t, filterProperty["properties"], "color")
+}
+
+func TestConvertSchemaToMCP_Nullable(t *testing.T) {
+	nullableString := openapi3.NewStringSchema()
+	nullableString.Nullable = true
+	nullableEnum := openapi3.NewStringSchema().WithEnum("a", "b")
+	nullableEnum.Nullable = true
+	typeList := &openapi3.Schema{Type: &openapi3.Types{"null", "integer"}}
+
+	pet := openapi3.NewObjectSchema().
+		WithProperty("nickname", nullableString).
+		WithProperty("kind", nullableEnum).
+		WithProperty("age", typeList)
+
+	schema := convertSchemaToMCP(pet.NewRef())
+	properties := schema["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{"type": []string{"string", "null"}}, properties["nickname"])
+	assert.Equal(t, map[string]any{"type": []string{"string", "null"}, "enum": []any{"a", "b", nil}}, properties["kind"])
+	assert.Equal(t, map[string]any{"type": []string{"integer", "null"}}, properties["age"])
+	assert.Equal(t, []any{"a", "b"}, nullableEnum.Enum)
+
+	// The output schema accepts the null values the API may return
+	op := &openapi3.Operation{Responses: jsonResponses("200", pet)}
+	output := newToolOutput(op)
+	require.NotNil(t, output)
+	data, err := json.Marshal(output.schema)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"nickname":{"type":["string","null"]}`)
+}
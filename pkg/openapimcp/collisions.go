@@ -0,0 +1,120 @@
+package openapimcp
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// BodyCollisionStrategy decides how body fields whose names collide with
+// parameter names are exposed as tool arguments.
+type BodyCollisionStrategy string
+
+const (
+	// PrefixBodyFields exposes a colliding body field id as body_id
+	PrefixBodyFields BodyCollisionStrategy = "prefix"
+	// NestBodyFields exposes colliding body fields as properties of a body object argument
+	NestBodyFields BodyCollisionStrategy = "nest"
+)
+
+// bodyFieldPrefix is prepended to colliding body field names
+const bodyFieldPrefix = bodyArgument + "_"
+
+// parameterArguments returns the names of the tool arguments carrying the
+// operation's parameters
+func parameterArguments(op *openapi3.Operation) map[string]bool {
+	taken := map[string]bool{}
+	for _, paramRef := range op.Parameters {
+		if param := paramRef.Value; param != nil && !isHidden(param.Extensions) {
+			taken[param.Name] = true
+		}
+	}
+	return taken
+}
+
+// bodyArgumentName returns the tool argument carrying a body that is passed
+// as a whole, prefixed when a parameter is named body as well
+func bodyArgumentName(op *openapi3.Operation) string {
+	return uniqueArgument(bodyArgument, parameterArguments(op))
+}
+
+// bodyFieldArguments returns, for every property of a flattened body, the
+// path of the tool argument carrying it. Properties colliding with a visible
+// parameter are renamed or nested according to the collision strategy.
+func (b *MCPServerBuilder) bodyFieldArguments(op *openapi3.Operation, schemaRef *openapi3.SchemaRef) map[string][]string {
+	arguments := map[string][]string{}
+	if schemaRef == nil || schemaRef.Value == nil {
+		return arguments
+	}
+
+	taken := parameterArguments(op)
+	colliding := []string{}
+	for name := range schemaRef.Value.Properties {
+		if taken[name] {
+			colliding = append(colliding, name)
+		} else {
+			arguments[name] = []string{name}
+		}
+	}
+	if len(colliding) == 0 {
+		return arguments
+	}
+	sort.Strings(colliding)
+
+	// Field arguments claim their names before colliding fields are renamed
+	for _, path := range arguments {
+		taken[path[0]] = true
+	}
+
+	if b.config.BodyCollisions == NestBodyFields {
+		nested := uniqueArgument(bodyArgument, taken)
+		for _, name := range colliding {
+			arguments[name] = []string{nested, name}
+		}
+		return arguments
+	}
+
+	for _, name := range colliding {
+		renamed := uniqueArgument(bodyFieldPrefix+name, taken)
+		taken[renamed] = true
+		arguments[name] = []string{renamed}
+	}
+	return arguments
+}
+
+// uniqueArgument prefixes name until it no longer collides with a taken name
+func uniqueArgument(name string, taken map[string]bool) string {
+	for taken[name] {
+		name = bodyFieldPrefix + name
+	}
+	return name
+}
+
+// argumentContainer returns the arguments holding the last element of path
+// and its name, descending into nested argument objects
+func argumentContainer(args map[string]any, path []string) (map[string]any, string) {
+	container := args
+	for _, name := range path[:len(path)-1] {
+		nested, _ := container[name].(map[string]any)
+		container = nested
+	}
+	return container, path[len(path)-1]
+}
+
+// renameViolations reports violations of renamed or nested body fields under
+// the argument path the caller used
+func renameViolations(violations []string, arguments map[string][]string) []string {
+	for i, violation := range violations {
+		for field, path := range arguments {
+			if len(path) == 1 && path[0] == field {
+				continue
+			}
+			if rest, ok := strings.CutPrefix(violation, field); ok && (strings.HasPrefix(rest, ".") || strings.HasPrefix(rest, ":")) {
+				violations[i] = strings.Join(path, ".") + rest
+				break
+			}
+		}
+	}
+	return violations
+}
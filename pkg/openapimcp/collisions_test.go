@@ -0,0 +1,98 @@
+package openapimcp
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func collisionSpec() *openapi3.T {
+	pet := openapi3.NewObjectSchema().
+		WithProperty("id", openapi3.NewIntegerSchema()).
+		WithProperty("tag", openapi3.NewStringSchema())
+	pet.Required = []string{"id"}
+
+	return &openapi3.T{Paths: openapi3.NewPaths(openapi3.WithPath("/pets/{id}", &openapi3.PathItem{
+		Put: &openapi3.Operation{
+			OperationID: "updatePet",
+			Parameters: openapi3.Parameters{
+				{Value: openapi3.NewPathParameter("id").WithSchema(openapi3.NewStringSchema())},
+			},
+			RequestBody: &openapi3.RequestBodyRef{Value: openapi3.NewRequestBody().WithJSONSchema(pet)},
+			Responses:   openapi3.NewResponses(),
+		},
+	}))}
+}
+
+func TestBuildMCPServerFromSpec_BodyFieldCollisions(t *testing.T) {
+	tests := []struct {
+		name       string
+		strategy   BodyCollisionStrategy
+		properties []string
+		args       map[string]any
+		violation  string
+	}{
+		{
+			name:       "prefix",
+			strategy:   PrefixBodyFields,
+			properties: []string{"body_id", "id", "tag"},
+			args:       map[string]any{"id": "a1", "body_id": 7, "tag": "cat"},
+			violation:  "body_id: required field is missing",
+		},
+		{
+			name:       "nest",
+			strategy:   NestBodyFields,
+			properties: []string{"body", "id", "tag"},
+			args:       map[string]any{"id": "a1", "body": map[string]any{"id": 7}, "tag": "cat"},
+			violation:  "body.id: required field is missing",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotPath, gotBody string
+			backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				data, _ := io.ReadAll(r.Body)
+				gotPath, gotBody = r.URL.Path, string(data)
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer backend.Close()
+
+			mcpServer, err := BuildMCPServerFromSpec(collisionSpec(), &APIConfig{BaseURL: backend.URL, BodyCollisions: tt.strategy})
+			require.NoError(t, err)
+
+			tools := listTools(t, mcpServer)
+			require.Len(t, tools, 1)
+			properties := []string{}
+			for name := range tools[0].InputSchema.Properties {
+				properties = append(properties, name)
+			}
+			assert.ElementsMatch(t, tt.properties, properties)
+			assert.ElementsMatch(t, []string{"id", tt.properties[0]}, tools[0].InputSchema.Required)
+
+			result := callTool(t, mcpServer, "updatePet", tt.args)
+			require.False(t, result.IsError, result.Content)
+			assert.Equal(t, "/pets/a1", gotPath)
+			assert.JSONEq(t, `{"id":7,"tag":"cat"}`, gotBody)
+
+			result = callTool(t, mcpServer, "updatePet", map[string]any{"id": "a1"})
+			require.True(t, result.IsError)
+			assert.Contains(t, result.Content[0].(mcp.TextContent).Text, tt.violation)
+		})
+	}
+}
+
+func TestBodyArgumentName(t *testing.T) {
+	op := &openapi3.Operation{Parameters: openapi3.Parameters{
+		{Value: openapi3.NewQueryParameter("body")},
+		{Value: openapi3.NewQueryParameter("body_body")},
+	}}
+	assert.Equal(t, "body_body_body", bodyArgumentName(op))
+	assert.Equal(t, "body", bodyArgumentName(&openapi3.Operation{}))
+}
@@ -0,0 +1,122 @@
+package openapimcp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// sessionJarIdleTimeout is how long the jar of a session without calls is kept
+	sessionJarIdleTimeout = 30 * time.Minute
+	// maxSessionJars bounds the jars kept, the least recently used is dropped first
+	maxSessionJars = 1000
+)
+
+// sessionJars keeps the cookies set by the API in a separate jar per MCP
+// client session, so that session-cookie based APIs work across tool calls
+// without leaking cookies between clients. Jars are dropped once idle or when
+// too many are kept, rather than when a session ends: the streamable HTTP
+// transport does not report the end of its sessions.
+type sessionJars struct {
+	mu   sync.Mutex
+	jars map[string]*sessionJar
+
+	idleTimeout time.Duration
+	maxJars     int
+	now         func() time.Time
+}
+
+type sessionJar struct {
+	jar      http.CookieJar
+	lastUsed time.Time
+}
+
+func newSessionJars() *sessionJars {
+	return &sessionJars{
+		jars:        map[string]*sessionJar{},
+		idleTimeout: sessionJarIdleTimeout,
+		maxJars:     maxSessionJars,
+		now:         time.Now,
+	}
+}
+
+// client returns a copy of base that stores cookies in the jar of the session
+// in ctx. Calls made outside of a session share a single jar.
+func (s *sessionJars) client(ctx context.Context, base *http.Client) *http.Client {
+	sessionID := ""
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		sessionID = session.SessionID()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.evictIdle(now)
+
+	entry, ok := s.jars[sessionID]
+	if !ok {
+		s.evictOldest(s.maxJars - 1)
+		// cookiejar.New only fails on invalid options
+		jar, _ := cookiejar.New(nil)
+		entry = &sessionJar{jar: jar}
+		s.jars[sessionID] = entry
+	}
+	entry.lastUsed = now
+
+	client := *base
+	client.Jar = entry.jar
+	return &client
+}
+
+// evictIdle drops the jars that have not been used for the idle timeout
+func (s *sessionJars) evictIdle(now time.Time) {
+	for id, entry := range s.jars {
+		if now.Sub(entry.lastUsed) > s.idleTimeout {
+			delete(s.jars, id)
+		}
+	}
+}
+
+// evictOldest drops the least recently used jars until at most n are left
+func (s *sessionJars) evictOldest(n int) {
+	for len(s.jars) > n {
+		var oldestID string
+		var oldest *sessionJar
+		for id, entry := range s.jars {
+			if oldest == nil || entry.lastUsed.Before(oldest.lastUsed) {
+				oldestID, oldest = id, entry
+			}
+		}
+		delete(s.jars, oldestID)
+	}
+}
+
+// httpClient returns the client to send API requests of the session in ctx with
+func (b *MCPServerBuilder) httpClient(ctx context.Context) *http.Client {
+	if b.cookieJars == nil {
+		return b.config.HTTPClient
+	}
+	return b.cookieJars.client(ctx, b.config.HTTPClient)
+}
+
+// escapeCookieValue percent-encodes the characters that are not allowed in a
+// cookie value by RFC 6265, along with the percent sign itself
+func escapeCookieValue(s string) string {
+	escaped := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < 0x21 || c > 0x7e || c == '"' || c == ',' || c == ';' || c == '\\' || c == '%' {
+			escaped = append(escaped, fmt.Sprintf("%%%02X", c)...)
+			continue
+		}
+		escaped = append(escaped, c)
+	}
+	return string(escaped)
+}
@@ -0,0 +1,161 @@
+package openapimcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSession struct {
+	id string
+}
+
+func (s fakeSession) Initialize()                                         {}
+func (s fakeSession) Initialized() bool                                   { return true }
+func (s fakeSession) NotificationChannel() chan<- mcp.JSONRPCNotification { return nil }
+func (s fakeSession) SessionID() string                                   { return s.id }
+
+func cookieSpec() *openapi3.T {
+	return &openapi3.T{Paths: openapi3.NewPaths(
+		openapi3.WithPath("/login", &openapi3.PathItem{
+			Post: &openapi3.Operation{OperationID: "login", Responses: openapi3.NewResponses()},
+		}),
+		openapi3.WithPath("/me", &openapi3.PathItem{
+			Get: &openapi3.Operation{
+				OperationID: "me",
+				Parameters: openapi3.Parameters{
+					{Value: &openapi3.Parameter{Name: "locale", In: "cookie", Schema: openapi3.NewStringSchema().NewRef()}},
+				},
+				Responses: openapi3.NewResponses(),
+			},
+		}),
+	)}
+}
+
+func TestBuildMCPServerFromSpec_CookieParameters(t *testing.T) {
+	var gotCookie string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotCookie = r.Header.Get("Cookie")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer backend.Close()
+
+	mcpServer, err := BuildMCPServerFromSpec(cookieSpec(), &APIConfig{BaseURL: backend.URL})
+	require.NoError(t, err)
+
+	callTool(t, mcpServer, "me", map[string]any{"locale": "en US;q=1"})
+	assert.Equal(t, "locale=en%20US%3Bq=1", gotCookie)
+}
+
+func TestBuildMCPServerFromSpec_SessionCookies(t *testing.T) {
+	var gotCookie string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc", Path: "/"})
+		}
+		gotCookie = r.Header.Get("Cookie")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer backend.Close()
+
+	mcpServer, err := BuildMCPServerFromSpec(cookieSpec(), &APIConfig{BaseURL: backend.URL, SessionCookies: true})
+	require.NoError(t, err)
+
+	callTool(t, mcpServer, "login", map[string]any{})
+	callTool(t, mcpServer, "me", map[string]any{"locale": "en"})
+	assert.Equal(t, "locale=en; session=abc", gotCookie)
+
+	// Over streamable HTTP the GET stream of a session registers and
+	// unregisters it, which must not drop the cookies of its POST calls
+	mcpServer, err = BuildMCPServerFromSpec(cookieSpec(), &APIConfig{BaseURL: backend.URL, SessionCookies: true})
+	require.NoError(t, err)
+	handler := server.NewStreamableHTTPServer(mcpServer)
+
+	sessionA := streamableHTTPCall(t, handler, "", "initialize", map[string]any{"protocolVersion": "2025-03-26", "capabilities": map[string]any{}, "clientInfo": map[string]any{"name": "a", "version": "1"}})
+	sessionB := streamableHTTPCall(t, handler, "", "initialize", map[string]any{"protocolVersion": "2025-03-26", "capabilities": map[string]any{}, "clientInfo": map[string]any{"name": "b", "version": "1"}})
+	require.NotEmpty(t, sessionA)
+	require.NotEqual(t, sessionA, sessionB)
+
+	streamableHTTPCall(t, handler, sessionA, "tools/call", map[string]any{"name": "login", "arguments": map[string]any{}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	stream := httptest.NewRequest(http.MethodGet, "/mcp", nil).WithContext(ctx)
+	stream.Header.Set(server.HeaderKeySessionID, sessionA)
+	handler.ServeHTTP(httptest.NewRecorder(), stream)
+
+	streamableHTTPCall(t, handler, sessionA, "tools/call", map[string]any{"name": "me", "arguments": map[string]any{}})
+	assert.Equal(t, "session=abc", gotCookie)
+
+	streamableHTTPCall(t, handler, sessionB, "tools/call", map[string]any{"name": "me", "arguments": map[string]any{}})
+	assert.Empty(t, gotCookie)
+
+	// cookies are not shared when disabled
+	mcpServer, err = BuildMCPServerFromSpec(cookieSpec(), &APIConfig{BaseURL: backend.URL})
+	require.NoError(t, err)
+
+	callTool(t, mcpServer, "login", map[string]any{})
+	callTool(t, mcpServer, "me", map[string]any{})
+	assert.Empty(t, gotCookie)
+}
+
+func TestSessionJars(t *testing.T) {
+	jars := newSessionJars()
+	mcpServer := server.NewMCPServer("test", "1.0.0")
+	base := &http.Client{}
+
+	ctxA := mcpServer.WithContext(context.Background(), fakeSession{id: "a"})
+	ctxB := mcpServer.WithContext(context.Background(), fakeSession{id: "b"})
+
+	clientA := jars.client(ctxA, base)
+	assert.Same(t, clientA.Jar, jars.client(ctxA, base).Jar)
+	assert.NotSame(t, clientA.Jar, jars.client(ctxB, base).Jar)
+	assert.Nil(t, base.Jar)
+
+	// Jars are dropped once idle
+	now := time.Now()
+	jars.now = func() time.Time { return now }
+	clientA = jars.client(ctxA, base)
+	now = now.Add(sessionJarIdleTimeout + time.Second)
+	assert.NotSame(t, clientA.Jar, jars.client(ctxA, base).Jar)
+
+	// and the least recently used one goes first when there are too many
+	jars.maxJars = 2
+	clientA = jars.client(ctxA, base)
+	now = now.Add(time.Second)
+	clientB := jars.client(ctxB, base)
+	now = now.Add(time.Second)
+	jars.client(mcpServer.WithContext(context.Background(), fakeSession{id: "c"}), base)
+	assert.Len(t, jars.jars, 2)
+	assert.Same(t, clientB.Jar, jars.client(ctxB, base).Jar)
+	assert.NotSame(t, clientA.Jar, jars.client(ctxA, base).Jar)
+}
+
+// streamableHTTPCall sends a JSON-RPC request to a streamable HTTP handler
+// and returns the session ID of the response
+func streamableHTTPCall(t *testing.T, handler http.Handler, sessionID, method string, params map[string]any) string {
+	t.Helper()
+
+	msg, err := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": 1, "method": method, "params": params})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/mcp", bytes.NewReader(msg))
+	req.Header.Set("Content-Type", "application/json")
+	if sessionID != "" {
+		req.Header.Set(server.HeaderKeySessionID, sessionID)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	return rec.Header().Get(server.HeaderKeySessionID)
+}
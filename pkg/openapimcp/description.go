@@ -0,0 +1,213 @@
+package openapimcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	log "github.com/sirupsen/logrus"
+)
+
+// maxSummaryProperties limits the properties listed in a response summary
+const maxSummaryProperties = 8
+
+// defaultDescriptionTemplate combines the summary, description, deprecation
+// notice, tags, response summary and parameter examples of an operation. The
+// summary is left out when the description already starts with it.
+const defaultDescriptionTemplate = `{{if and .Summary (not (hasPrefix .Description .Summary))}}{{.Summary}}{{if .Description}}
+
+{{end}}{{end}}{{.Description}}
+{{- if .Deprecated}}
+
+Deprecated: this operation may be removed, prefer an alternative when one exists.{{end}}
+{{- if .Tags}}
+
+Tags: {{join .Tags ", "}}{{end}}
+{{- if .Returns}}
+
+Returns: {{.Returns}}{{end}}
+{{- if .Examples}}
+
+Examples: {{range $i, $e := .Examples}}{{if $i}}, {{end}}{{$e.Name}}={{$e.Value}}{{end}}{{end}}`
+
+var descriptionFuncs = template.FuncMap{
+	"join":      strings.Join,
+	"hasPrefix": strings.HasPrefix,
+}
+
+var defaultDescription = template.Must(template.New("description").Funcs(descriptionFuncs).Parse(defaultDescriptionTemplate))
+
+// ToolDescriptionData is the data tool description templates are executed with
+type ToolDescriptionData struct {
+	OperationID string
+	Summary     string
+	Description string
+	Tags        []string
+	Deprecated  bool
+	Returns     string // Compact summary of the success response schema, e.g. array of Pet {id, name}
+	Examples    []ToolDescriptionExample
+}
+
+// ToolDescriptionExample is an example value of a parameter, encoded as JSON
+type ToolDescriptionExample struct {
+	Name  string
+	Value string
+}
+
+// parseDescriptionTemplate parses a tool description template, the default
+// template is used when text is empty
+func parseDescriptionTemplate(text string) (*template.Template, error) {
+	if text == "" {
+		return defaultDescription, nil
+	}
+	tmpl, err := template.New("description").Funcs(descriptionFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid description template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// toolDescription renders the description of an operation's tool, limited to
+// the configured maximum length. A description set with x-mcp-description is
+// used as it is.
+func (b *MCPServerBuilder) toolDescription(op *openapi3.Operation) string {
+	description := extensionString(op.Extensions, extDescription)
+	if description == "" {
+		description = renderDescription(b.descriptionTemplate, newToolDescriptionData(op))
+	}
+	if b.config == nil {
+		return description
+	}
+	return truncateDescription(description, b.config.DescriptionMaxLength)
+}
+
+func renderDescription(tmpl *template.Template, data ToolDescriptionData) string {
+	if tmpl == nil {
+		tmpl = defaultDescription
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		log.Warnf("Failed to render description of %s, using the default template: %v", data.OperationID, err)
+		buf.Reset()
+		//nolint
+		defaultDescription.Execute(&buf, data)
+	}
+	return strings.TrimSpace(buf.String())
+}
+
+func newToolDescriptionData(op *openapi3.Operation) ToolDescriptionData {
+	return ToolDescriptionData{
+		OperationID: op.OperationID,
+		Summary:     strings.TrimSpace(op.Summary),
+		Description: strings.TrimSpace(op.Description),
+		Tags:        op.Tags,
+		Deprecated:  op.Deprecated,
+		Returns:     summarizeSchema(successResponseSchema(op), 0),
+		Examples:    parameterExamples(op),
+	}
+}
+
+// parameterExamples returns the example value of every visible parameter
+// declaring one. Schema examples are left out, the input schema carries them.
+func parameterExamples(op *openapi3.Operation) []ToolDescriptionExample {
+	examples := []ToolDescriptionExample{}
+	for _, paramRef := range op.Parameters {
+		param := paramRef.Value
+		if param == nil || isHidden(param.Extensions) {
+			continue
+		}
+
+		example := param.Example
+		if values := parameterExampleValues(param); example == nil && len(values) > 0 {
+			example = values[0]
+		}
+		if example == nil {
+			continue
+		}
+
+		value, err := json.Marshal(example)
+		if err != nil {
+			continue
+		}
+		examples = append(examples, ToolDescriptionExample{Name: param.Name, Value: string(value)})
+	}
+	return examples
+}
+
+// summarizeSchema describes a schema in a few words, e.g. array of Pet {id, name}
+func summarizeSchema(schemaRef *openapi3.SchemaRef, depth int) string {
+	if schemaRef == nil || schemaRef.Value == nil || depth > 3 {
+		return ""
+	}
+	schema := schemaRef.Value
+
+	compositions := []struct {
+		label   string
+		schemas openapi3.SchemaRefs
+	}{{"one of", schema.OneOf}, {"any of", schema.AnyOf}, {"all of", schema.AllOf}}
+	for _, composition := range compositions {
+		if len(composition.schemas) == 0 {
+			continue
+		}
+		parts := []string{}
+		for _, s := range composition.schemas {
+			if part := summarizeSchema(s, depth+1); part != "" {
+				parts = append(parts, part)
+			}
+		}
+		return composition.label + " " + strings.Join(parts, " | ")
+	}
+
+	switch {
+	case schema.Type.Is("array"):
+		if items := summarizeSchema(schema.Items, depth+1); items != "" {
+			return "array of " + items
+		}
+		return "array"
+	case schema.Type.Is("object") || len(schema.Properties) > 0:
+		summary := "object"
+		if schemaRef.Ref != "" {
+			summary = extractRefName(schemaRef.Ref)
+		}
+		if len(schema.Properties) == 0 {
+			return summary
+		}
+		names := make([]string, 0, len(schema.Properties))
+		for name, prop := range schema.Properties {
+			if prop == nil || prop.Value == nil || !isHidden(prop.Value.Extensions) {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		if len(names) > maxSummaryProperties {
+			names = append(names[:maxSummaryProperties], "...")
+		}
+		return summary + " {" + strings.Join(names, ", ") + "}"
+	case schema.Type != nil && len(*schema.Type) > 0:
+		summary := strings.Join(*schema.Type, " or ")
+		if schema.Format != "" {
+			summary += " (" + schema.Format + ")"
+		}
+		return summary
+	default:
+		return ""
+	}
+}
+
+// truncateDescription cuts a description to at most maxLength characters,
+// marking the cut with an ellipsis. A maxLength of zero disables the limit.
+func truncateDescription(description string, maxLength int) string {
+	runes := []rune(description)
+	if maxLength <= 0 || len(runes) <= maxLength {
+		return description
+	}
+	if maxLength <= 3 {
+		return string(runes[:maxLength])
+	}
+	return strings.TrimSpace(string(runes[:maxLength-3])) + "..."
+}
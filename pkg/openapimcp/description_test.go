@@ -0,0 +1,108 @@
+package openapimcp
+
+import (
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func describedOperation() *openapi3.Operation {
+	pet := openapi3.NewObjectSchema().
+		WithProperty("id", openapi3.NewIntegerSchema()).
+		WithProperty("name", openapi3.NewStringSchema())
+
+	return &openapi3.Operation{
+		OperationID: "listPets",
+		Summary:     "List pets",
+		Description: "Returns the pets of the store.",
+		Tags:        []string{"pets", "store"},
+		Deprecated:  true,
+		Parameters: openapi3.Parameters{
+			{Value: &openapi3.Parameter{Name: "limit", In: "query", Example: 10}},
+			{Value: &openapi3.Parameter{Name: "status", In: "query", Examples: openapi3.Examples{
+				"sold":      {Value: openapi3.NewExample("sold")},
+				"available": {Value: openapi3.NewExample("available")},
+			}}},
+			{Value: &openapi3.Parameter{Name: "tenant", In: "header", Example: "acme", Extensions: map[string]any{"x-mcp-hidden": true}}},
+		},
+		Responses: jsonResponses("200", openapi3.NewArraySchema().WithItems(pet)),
+	}
+}
+
+func TestToolDescription(t *testing.T) {
+	tests := []struct {
+		name string
+		op   *openapi3.Operation
+		want string
+	}{
+		{
+			name: "summary only",
+			op:   &openapi3.Operation{Summary: "List pets"},
+			want: "List pets",
+		},
+		{
+			name: "description starting with the summary",
+			op:   &openapi3.Operation{Summary: "List pets", Description: "List pets of the store."},
+			want: "List pets of the store.",
+		},
+		{
+			name: "everything",
+			op:   describedOperation(),
+			want: "List pets\n\nReturns the pets of the store.\n\n" +
+				"Deprecated: this operation may be removed, prefer an alternative when one exists.\n\n" +
+				"Tags: pets, store\n\n" +
+				"Returns: array of object {id, name}\n\n" +
+				`Examples: limit=10, status="available"`,
+		},
+		{
+			name: "extension",
+			op:   &openapi3.Operation{Summary: "List pets", Extensions: map[string]any{"x-mcp-description": "Custom"}},
+			want: "Custom",
+		},
+	}
+
+	builder := NewMCPServerBuilder(&APIConfig{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, builder.toolDescription(tt.op))
+		})
+	}
+}
+
+func TestToolDescription_TemplateAndMaxLength(t *testing.T) {
+	builder := NewMCPServerBuilder(&APIConfig{
+		DescriptionTemplate:  `{{.Summary}} [{{join .Tags "/"}}] -> {{.Returns}}`,
+		DescriptionMaxLength: 30,
+	})
+	tmpl, err := parseDescriptionTemplate(builder.config.DescriptionTemplate)
+	require.NoError(t, err)
+	builder.descriptionTemplate = tmpl
+
+	assert.Equal(t, "List pets [pets/store] -> a...", builder.toolDescription(describedOperation()))
+
+	_, err = parseDescriptionTemplate("{{.Summary")
+	assert.Error(t, err)
+}
+
+func TestSummarizeSchema(t *testing.T) {
+	pet := openapi3.NewObjectSchema().WithProperty("id", openapi3.NewIntegerSchema())
+
+	tests := []struct {
+		name   string
+		schema *openapi3.SchemaRef
+		want   string
+	}{
+		{"named object", &openapi3.SchemaRef{Ref: "#/components/schemas/Pet", Value: pet}, "Pet {id}"},
+		{"formatted string", openapi3.NewDateTimeSchema().NewRef(), "string (date-time)"},
+		{"one of", openapi3.NewOneOfSchema(openapi3.NewStringSchema(), pet).NewRef(), "one of string | object {id}"},
+		{"nil", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, summarizeSchema(tt.schema, 0))
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package openapimcp
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// problemMediaType is the RFC 7807 problem details media type
+const problemMediaType = "application/problem+json"
+
+// apiError is the error payload returned to the model for a failed API call.
+// RFC 7807 problem details are mapped onto the same fields so that every
+// error has a consistent shape.
+type apiError struct {
+	Status      int    `json:"status"`
+	Title       string `json:"title"`
+	Type        string `json:"type,omitempty"`
+	Detail      string `json:"detail,omitempty"`
+	Instance    string `json:"instance,omitempty"`
+	Description string `json:"description,omitempty"` // Description of the documented response for the status
+	Schema      string `json:"schema,omitempty"`      // Model of the documented response body
+	Body        any    `json:"body,omitempty"`        // Response body, or the extension members of a problem
+}
+
+// newAPIError builds the error payload for a 4xx/5xx response of the operation
+func newAPIError(op *openapi3.Operation, resp *apiResponse) *apiError {
+	apiErr := &apiError{
+		Status: resp.StatusCode,
+		Title:  http.StatusText(resp.StatusCode),
+	}
+
+	if response := documentedResponse(op, resp.StatusCode); response != nil {
+		if response.Description != nil {
+			apiErr.Description = *response.Description
+		}
+		if schema := jsonSchema(response.Content); schema != nil && schema.Ref != "" {
+			apiErr.Schema = extractRefName(schema.Ref)
+		}
+	}
+
+	if len(resp.Body) == 0 {
+		return apiErr
+	}
+
+	var body any
+	if err := json.Unmarshal(resp.Body, &body); err != nil {
+		apiErr.Body = string(resp.Body)
+		return apiErr
+	}
+
+	problem, ok := body.(map[string]any)
+	if !ok || !isProblem(resp.Header) {
+		apiErr.Body = body
+		return apiErr
+	}
+
+	members := map[string]*string{
+		"title":    &apiErr.Title,
+		"type":     &apiErr.Type,
+		"detail":   &apiErr.Detail,
+		"instance": &apiErr.Instance,
+	}
+	for name, field := range members {
+		if value, ok := problem[name].(string); ok {
+			*field = value
+			delete(problem, name)
+		}
+	}
+	// The status of the problem is advisory, the response status is authoritative
+	delete(problem, "status")
+
+	if len(problem) > 0 {
+		apiErr.Body = problem
+	}
+	return apiErr
+}
+
+// toolResult converts the error into a tool result flagged as an error
+func (e *apiError) toolResult() *mcp.CallToolResult {
+	text, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultErrorf("Status: %d %s", e.Status, e.Title)
+	}
+	return mcp.NewToolResultError(string(text))
+}
+
+// isProblem reports whether the response carries RFC 7807 problem details
+func isProblem(header http.Header) bool {
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	return err == nil && mediaType == problemMediaType
+}
+
+// documentedResponse returns the response the operation documents for the
+// status, falling back to its status range and the default response.
+func documentedResponse(op *openapi3.Operation, status int) *openapi3.Response {
+	if op.Responses == nil {
+		return nil
+	}
+
+	responseRef := op.Responses.Status(status)
+	if responseRef == nil {
+		responseRef = op.Responses.Default()
+	}
+	if responseRef == nil {
+		return nil
+	}
+	return responseRef.Value
+}
@@ -0,0 +1,109 @@
+package openapimcp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAPIError(t *testing.T) {
+	validationError := openapi3.NewResponse().WithDescription("Validation Error")
+	validationError.Content = openapi3.Content{"application/json": &openapi3.MediaType{
+		Schema: &openapi3.SchemaRef{Ref: "#/components/schemas/HTTPValidationError", Value: openapi3.NewObjectSchema()},
+	}}
+	op := &openapi3.Operation{Responses: openapi3.NewResponses(
+		openapi3.WithName("422", validationError),
+		openapi3.WithName("5XX", openapi3.NewResponse().WithDescription("Server error")),
+		openapi3.WithName("default", openapi3.NewResponse().WithDescription("Unexpected error")),
+	)}
+
+	tests := []struct {
+		name     string
+		resp     *apiResponse
+		expected *apiError
+	}{
+		{
+			name: "documented status",
+			resp: &apiResponse{StatusCode: 422, Header: http.Header{"Content-Type": {"application/json"}}, Body: []byte(`{"detail":[{"msg":"field required"}]}`)},
+			expected: &apiError{
+				Status: 422, Title: "Unprocessable Entity",
+				Description: "Validation Error", Schema: "HTTPValidationError",
+				Body: map[string]any{"detail": []any{map[string]any{"msg": "field required"}}},
+			},
+		},
+		{
+			name:     "status range",
+			resp:     &apiResponse{StatusCode: 503, Body: []byte("upstream unavailable")},
+			expected: &apiError{Status: 503, Title: "Service Unavailable", Description: "Server error", Body: "upstream unavailable"},
+		},
+		{
+			name:     "default response",
+			resp:     &apiResponse{StatusCode: 404},
+			expected: &apiError{Status: 404, Title: "Not Found", Description: "Unexpected error"},
+		},
+		{
+			name: "problem details",
+			resp: &apiResponse{
+				StatusCode: 403,
+				Header:     http.Header{"Content-Type": {"application/problem+json; charset=utf-8"}},
+				Body:       []byte(`{"type":"https://example.com/probs/out-of-credit","title":"You do not have enough credit.","status":403,"detail":"Your current balance is 30, but that costs 50.","instance":"/account/12345/msgs/abc","balance":30}`),
+			},
+			expected: &apiError{
+				Status:      403,
+				Title:       "You do not have enough credit.",
+				Type:        "https://example.com/probs/out-of-credit",
+				Detail:      "Your current balance is 30, but that costs 50.",
+				Instance:    "/account/12345/msgs/abc",
+				Description: "Unexpected error",
+				Body:        map[string]any{"balance": float64(30)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, newAPIError(op, tt.resp))
+		})
+	}
+}
+
+func TestBuildMCPServerFromSpec_ErrorResponses(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.Header().Set("Content-Type", problemMediaType)
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"title":"Pet not found","detail":"No pet with id 7"}`))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer backend.Close()
+
+	spec := &openapi3.T{Paths: openapi3.NewPaths(
+		openapi3.WithPath("/missing", &openapi3.PathItem{
+			Get: &openapi3.Operation{OperationID: "getMissing", Responses: openapi3.NewResponses()},
+		}),
+		openapi3.WithPath("/ok", &openapi3.PathItem{
+			Get: &openapi3.Operation{OperationID: "getOK", Responses: openapi3.NewResponses()},
+		}),
+	)}
+
+	mcpServer, err := BuildMCPServerFromSpec(spec, &APIConfig{BaseURL: backend.URL})
+	require.NoError(t, err)
+
+	result := callTool(t, mcpServer, "getMissing", map[string]any{})
+	assert.True(t, result.IsError)
+	require.Len(t, result.Content, 1)
+
+	var apiErr apiError
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &apiErr))
+	assert.Equal(t, apiError{Status: 404, Title: "Pet not found", Detail: "No pet with id 7"}, apiErr)
+
+	assert.False(t, callTool(t, mcpServer, "getOK", map[string]any{}).IsError)
+}
@@ -0,0 +1,74 @@
+package openapimcp
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Vendor extensions spec authors can use to customize the generated MCP surface.
+const (
+	// extName overrides the tool name of an operation
+	extName = "x-mcp-name"
+	// extDescription overrides the tool description of an operation
+	extDescription = "x-mcp-description"
+	// extHidden hides an operation, parameter or schema property from the tool
+	extHidden = "x-mcp-hidden"
+	// extAnnotations sets the tool annotations of an operation
+	extAnnotations = "x-mcp-annotations"
+	// extDefault is the value sent for a parameter or property the caller omits
+	extDefault = "x-mcp-default"
+)
+
+func extensionString(extensions map[string]any, key string) string {
+	if s, ok := extensions[key].(string); ok {
+		return s
+	}
+	return ""
+}
+
+// isHidden reports whether the extensions mark an element as hidden from the tool.
+func isHidden(extensions map[string]any) bool {
+	hidden, _ := extensions[extHidden].(bool)
+	return hidden
+}
+
+// extensionDefault returns the x-mcp-default value, if any.
+func extensionDefault(extensions map[string]any) (any, bool) {
+	value, ok := extensions[extDefault]
+	return value, ok
+}
+
+// applyAnnotationExtension overrides the annotation fields set in the
+// x-mcp-annotations object of an operation.
+func applyAnnotationExtension(annotations *mcp.ToolAnnotation, extensions map[string]any) {
+	values, ok := extensions[extAnnotations].(map[string]any)
+	if !ok {
+		return
+	}
+
+	if title, ok := values["title"].(string); ok {
+		annotations.Title = title
+	}
+	hints := map[string]**bool{
+		"readOnlyHint":    &annotations.ReadOnlyHint,
+		"destructiveHint": &annotations.DestructiveHint,
+		"idempotentHint":  &annotations.IdempotentHint,
+		"openWorldHint":   &annotations.OpenWorldHint,
+	}
+	for key, field := range hints {
+		if hint, ok := values[key].(bool); ok {
+			*field = &hint
+		}
+	}
+}
+
+// argumentValue returns the value to send for a parameter or body property: the
+// caller's argument, or the x-mcp-default when the caller omitted it or the
+// element is hidden and must not be supplied by the caller.
+func argumentValue(args map[string]any, name string, extensions map[string]any) (any, bool) {
+	if !isHidden(extensions) {
+		if value, exists := args[name]; exists {
+			return value, true
+		}
+	}
+	return extensionDefault(extensions)
+}
@@ -0,0 +1,282 @@
+package openapimcp
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// binaryDescription documents the values binary tool arguments accept
+const binaryDescription = "Base64 encoded content, a data: URI or a file:// URI of a file under the server's file roots."
+
+// binaryData is the content of a binary tool argument, given as base64 data,
+// a data: URI or a file:// URI
+type binaryData struct {
+	filename    string
+	contentType string // Media type of a data: URI or of the file extension
+	data        []byte
+	path        string // File streamed instead of data
+}
+
+// resolveBinary decodes the value of a binary tool argument. File URIs must
+// point into one of the configured file roots.
+func (b *MCPServerBuilder) resolveBinary(value any) (*binaryData, error) {
+	s, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("value must be a string")
+	}
+
+	switch {
+	case strings.HasPrefix(s, "file:"):
+		return b.resolveFileURI(s)
+	case strings.HasPrefix(s, "data:"):
+		return decodeDataURI(s)
+	default:
+		data, err := decodeBase64(s)
+		if err != nil {
+			return nil, fmt.Errorf("value is neither base64 data, a data: URI nor a file:// URI")
+		}
+		return &binaryData{data: data}, nil
+	}
+}
+
+func (b *MCPServerBuilder) resolveFileURI(uri string) (*binaryData, error) {
+	u, err := url.Parse(uri)
+	if err != nil || (u.Host != "" && u.Host != "localhost") || u.Path == "" {
+		return nil, fmt.Errorf("invalid file URI %s", uri)
+	}
+
+	path, err := b.readableFile(filepath.FromSlash(u.Path))
+	if err != nil {
+		return nil, err
+	}
+
+	return &binaryData{
+		filename:    filepath.Base(path),
+		contentType: mime.TypeByExtension(filepath.Ext(path)),
+		path:        path,
+	}, nil
+}
+
+// readableFile resolves path, following symlinks, and checks that it is a
+// regular file inside one of the configured file roots
+func (b *MCPServerBuilder) readableFile(path string) (string, error) {
+	if len(b.config.FileRoots) == 0 {
+		return "", fmt.Errorf("file references are disabled, no file roots are configured")
+	}
+
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", fmt.Errorf("cannot access file %s: %w", path, err)
+	}
+	resolved, err = filepath.Abs(resolved)
+	if err != nil {
+		return "", fmt.Errorf("cannot access file %s: %w", path, err)
+	}
+
+	for _, root := range b.config.FileRoots {
+		dir, err := filepath.EvalSymlinks(root)
+		if err != nil {
+			continue
+		}
+		if dir, err = filepath.Abs(dir); err != nil {
+			continue
+		}
+		if rel, err := filepath.Rel(dir, resolved); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			info, err := os.Stat(resolved)
+			if err != nil {
+				return "", fmt.Errorf("cannot access file %s: %w", path, err)
+			}
+			if !info.Mode().IsRegular() {
+				return "", fmt.Errorf("%s is not a regular file", path)
+			}
+			return resolved, nil
+		}
+	}
+	return "", fmt.Errorf("file %s is outside the configured file roots", path)
+}
+
+// decodeDataURI decodes an RFC 2397 data: URI
+func decodeDataURI(uri string) (*binaryData, error) {
+	meta, payload, ok := strings.Cut(strings.TrimPrefix(uri, "data:"), ",")
+	if !ok {
+		return nil, fmt.Errorf("invalid data URI")
+	}
+
+	isBase64 := strings.HasSuffix(meta, ";base64")
+	contentType := strings.TrimSuffix(meta, ";base64")
+
+	var data []byte
+	if isBase64 {
+		decoded, err := decodeBase64(payload)
+		if err != nil {
+			return nil, fmt.Errorf("invalid base64 data in data URI")
+		}
+		data = decoded
+	} else {
+		decoded, err := url.PathUnescape(payload)
+		if err != nil {
+			return nil, fmt.Errorf("invalid data URI: %w", err)
+		}
+		data = []byte(decoded)
+	}
+
+	return &binaryData{contentType: contentType, data: data}, nil
+}
+
+// decodeBase64 accepts padded and unpadded, standard and URL-safe base64
+func decodeBase64(s string) ([]byte, error) {
+	var err error
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		var data []byte
+		if data, err = encoding.DecodeString(s); err == nil {
+			return data, nil
+		}
+	}
+	return nil, err
+}
+
+// open returns a reader over the content and its length
+func (d *binaryData) open() (io.Reader, int64, error) {
+	if d.path == "" {
+		return bytes.NewReader(d.data), int64(len(d.data)), nil
+	}
+
+	file, err := os.Open(d.path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open %s: %w", d.path, err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		//nolint
+		file.Close()
+		return nil, 0, fmt.Errorf("failed to open %s: %w", d.path, err)
+	}
+	return file, info.Size(), nil
+}
+
+// base64 returns the content encoded as standard base64
+func (d *binaryData) base64() (string, error) {
+	if d.path == "" {
+		return base64.StdEncoding.EncodeToString(d.data), nil
+	}
+
+	data, err := os.ReadFile(d.path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", d.path, err)
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// isBinarySchema reports whether a schema describes file content
+func isBinarySchema(schemaRef *openapi3.SchemaRef) bool {
+	if schemaRef == nil || schemaRef.Value == nil {
+		return false
+	}
+	schema := schemaRef.Value
+	return (schema.Format == "binary" || schema.Format == "byte") && (schema.Type == nil || schema.Type.Is("string"))
+}
+
+// isBinaryMedia reports whether a request body media type carries raw file
+// content rather than structured fields
+func isBinaryMedia(mediaType string, media *openapi3.MediaType) bool {
+	parsed, _, err := mime.ParseMediaType(mediaType)
+	if err != nil {
+		return false
+	}
+	if parsed == "application/octet-stream" {
+		return true
+	}
+	return media != nil && media.Schema != nil && media.Schema.Value != nil &&
+		media.Schema.Value.Format == "binary" && (media.Schema.Value.Type == nil || media.Schema.Value.Type.Is("string"))
+}
+
+// describeBinaryProperties documents the accepted values of the binary
+// properties of a converted body schema
+func describeBinaryProperties(schemaRef *openapi3.SchemaRef, props map[string]any) {
+	if schemaRef.Value == nil {
+		return
+	}
+
+	for name, propSchema := range schemaRef.Value.Properties {
+		prop, ok := props[name].(map[string]any)
+		if !ok || propSchema == nil || propSchema.Value == nil {
+			continue
+		}
+
+		target := prop
+		if propSchema.Value.Type.Is("array") && isBinarySchema(propSchema.Value.Items) {
+			if items, ok := prop["items"].(map[string]any); ok {
+				target = items
+			}
+		} else if !isBinarySchema(propSchema) {
+			continue
+		}
+
+		if description, ok := target["description"].(string); ok && description != "" {
+			target["description"] = description + " " + binaryDescription
+		} else {
+			target["description"] = binaryDescription
+		}
+	}
+}
+
+// resolveBinaryFields replaces the values of binary body fields with their
+// content: base64 strings for JSON and form bodies, binaryData parts for
+// multipart bodies. It returns a violation for every value it cannot resolve.
+func (b *MCPServerBuilder) resolveBinaryFields(mediaType string, media *openapi3.MediaType, fields map[string]any) []string {
+	if media.Schema == nil || media.Schema.Value == nil {
+		return nil
+	}
+	parsed, _, _ := mime.ParseMediaType(mediaType)
+	multipartBody := parsed == mediaTypeMultipart
+
+	violations := []string{}
+	resolve := func(path string, value any) any {
+		data, err := b.resolveBinary(value)
+		if err != nil {
+			violations = append(violations, formatViolation([]string{path}, err.Error()))
+			return value
+		}
+		if multipartBody {
+			return data
+		}
+		encoded, err := data.base64()
+		if err != nil {
+			violations = append(violations, formatViolation([]string{path}, err.Error()))
+			return value
+		}
+		return encoded
+	}
+
+	for name, propSchema := range media.Schema.Value.Properties {
+		value, ok := fields[name]
+		if !ok || propSchema == nil || propSchema.Value == nil {
+			continue
+		}
+
+		switch {
+		case isBinarySchema(propSchema):
+			fields[name] = resolve(name, value)
+		case propSchema.Value.Type.Is("array") && isBinarySchema(propSchema.Value.Items):
+			items, ok := value.([]any)
+			if !ok {
+				continue
+			}
+			resolved := make([]any, len(items))
+			for i, item := range items {
+				resolved[i] = resolve(fmt.Sprintf("%s.%d", name, i), item)
+			}
+			fields[name] = resolved
+		}
+	}
+	return violations
+}
@@ -0,0 +1,141 @@
+package openapimcp
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveBinary(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "report.pdf"), []byte("%PDF"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), 0o600))
+	require.NoError(t, os.Symlink(filepath.Join(outside, "secret.txt"), filepath.Join(root, "link.txt")))
+
+	builder := NewMCPServerBuilder(&APIConfig{FileRoots: []string{root}})
+
+	data, err := builder.resolveBinary("aGVsbG8=")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("hello"), data.data)
+
+	data, err = builder.resolveBinary("data:image/png;base64,iVBORw==")
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", data.contentType)
+	assert.Equal(t, []byte{0x89, 'P', 'N', 'G'}, data.data)
+
+	data, err = builder.resolveBinary("file://" + filepath.ToSlash(filepath.Join(root, "report.pdf")))
+	require.NoError(t, err)
+	assert.Equal(t, "report.pdf", data.filename)
+	assert.Equal(t, "application/pdf", data.contentType)
+
+	_, err = builder.resolveBinary("file://" + filepath.ToSlash(filepath.Join(outside, "secret.txt")))
+	assert.ErrorContains(t, err, "outside the configured file roots")
+
+	_, err = builder.resolveBinary("file://" + filepath.ToSlash(filepath.Join(root, "link.txt")))
+	assert.ErrorContains(t, err, "outside the configured file roots")
+
+	_, err = builder.resolveBinary("not base64!")
+	assert.Error(t, err)
+
+	_, err = NewMCPServerBuilder(&APIConfig{}).resolveBinary("file://" + filepath.ToSlash(filepath.Join(root, "report.pdf")))
+	assert.ErrorContains(t, err, "no file roots are configured")
+}
+
+func TestBuildMCPServerFromSpec_FileUploads(t *testing.T) {
+	root := t.TempDir()
+	photo := filepath.Join(root, "photo.png")
+	require.NoError(t, os.WriteFile(photo, []byte("PNGDATA"), 0o600))
+	photoURI := "file://" + filepath.ToSlash(photo)
+
+	type received struct {
+		contentType   string
+		contentLength int64
+		body          []byte
+		filename      string
+		name          string
+	}
+	var got received
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = received{contentType: r.Header.Get("Content-Type"), contentLength: r.ContentLength}
+		if r.URL.Path == "/upload" {
+			require.NoError(t, r.ParseMultipartForm(1<<20))
+			file, header, err := r.FormFile("file")
+			require.NoError(t, err)
+			got.body, _ = io.ReadAll(file)
+			got.filename = header.Filename
+			got.name = r.FormValue("name")
+		} else {
+			got.body, _ = io.ReadAll(r.Body)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer backend.Close()
+
+	uploadSchema := openapi3.NewObjectSchema().
+		WithProperty("name", openapi3.NewStringSchema()).
+		WithProperty("file", openapi3.NewStringSchema().WithFormat("binary"))
+	avatarSchema := openapi3.NewObjectSchema().
+		WithProperty("avatar", openapi3.NewStringSchema().WithFormat("byte"))
+
+	spec := &openapi3.T{Paths: openapi3.NewPaths(
+		openapi3.WithPath("/upload", &openapi3.PathItem{Post: &openapi3.Operation{
+			OperationID: "upload",
+			RequestBody: &openapi3.RequestBodyRef{Value: openapi3.NewRequestBody().WithContent(openapi3.Content{
+				mediaTypeMultipart: {Schema: uploadSchema.NewRef()},
+			})},
+			Responses: openapi3.NewResponses(),
+		}}),
+		openapi3.WithPath("/raw", &openapi3.PathItem{Put: &openapi3.Operation{
+			OperationID: "putRaw",
+			RequestBody: &openapi3.RequestBodyRef{Value: openapi3.NewRequestBody().WithRequired(true).WithContent(openapi3.Content{
+				"application/octet-stream": {},
+			})},
+			Responses: openapi3.NewResponses(),
+		}}),
+		openapi3.WithPath("/avatar", &openapi3.PathItem{Put: &openapi3.Operation{
+			OperationID: "putAvatar",
+			RequestBody: &openapi3.RequestBodyRef{Value: openapi3.NewRequestBody().WithJSONSchema(avatarSchema)},
+			Responses:   openapi3.NewResponses(),
+		}}),
+	)}
+
+	mcpServer, err := BuildMCPServerFromSpec(spec, &APIConfig{BaseURL: backend.URL, FileRoots: []string{root}})
+	require.NoError(t, err)
+
+	for _, tool := range listTools(t, mcpServer) {
+		if tool.Name == "putRaw" {
+			assert.Contains(t, tool.InputSchema.Properties, "body")
+			assert.Equal(t, []string{"body"}, tool.InputSchema.Required)
+		}
+	}
+
+	result := callTool(t, mcpServer, "upload", map[string]any{"name": "holiday", "file": photoURI})
+	require.False(t, result.IsError, result.Content)
+	assert.Equal(t, "photo.png", got.filename)
+	assert.Equal(t, []byte("PNGDATA"), got.body)
+	assert.Equal(t, "holiday", got.name)
+
+	result = callTool(t, mcpServer, "putRaw", map[string]any{"body": photoURI})
+	require.False(t, result.IsError, result.Content)
+	assert.Equal(t, "application/octet-stream", got.contentType)
+	assert.Equal(t, int64(7), got.contentLength)
+	assert.Equal(t, []byte("PNGDATA"), got.body)
+
+	result = callTool(t, mcpServer, "putAvatar", map[string]any{"avatar": photoURI})
+	require.False(t, result.IsError, result.Content)
+	var avatar map[string]any
+	require.NoError(t, json.Unmarshal(got.body, &avatar))
+	assert.Equal(t, "UE5HREFUQQ==", avatar["avatar"])
+
+	result = callTool(t, mcpServer, "putRaw", map[string]any{})
+	assert.True(t, result.IsError)
+}
@@ -0,0 +1,153 @@
+package openapimcp
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// OperationFilter selects the operations that are exposed as tools. An operation
+// is exposed when it matches every include criterion that is set and none of the
+// exclude criteria. The zero value exposes every operation.
+type OperationFilter struct {
+	IncludeTags []string // Operation must have at least one of these tags
+	ExcludeTags []string // Operation must have none of these tags
+
+	IncludeOperationID string // Regular expression the operationId must match
+	ExcludeOperationID string // Regular expression the operationId must not match
+
+	IncludeMethods []string // HTTP methods to expose, case insensitive
+	ExcludeMethods []string // HTTP methods to hide, case insensitive
+
+	IncludePaths []string // Path globs, '*' matches within a segment and '**' across segments
+	ExcludePaths []string // Path globs to hide
+
+	ExcludeDeprecated bool // Hide operations marked deprecated
+}
+
+// operationMatcher is the compiled form of an OperationFilter.
+type operationMatcher struct {
+	filter OperationFilter
+
+	includeOperationID *regexp.Regexp
+	excludeOperationID *regexp.Regexp
+	includePaths       []*regexp.Regexp
+	excludePaths       []*regexp.Regexp
+}
+
+func newOperationMatcher(filter OperationFilter) (*operationMatcher, error) {
+	m := &operationMatcher{filter: filter}
+
+	var err error
+	if filter.IncludeOperationID != "" {
+		if m.includeOperationID, err = regexp.Compile(filter.IncludeOperationID); err != nil {
+			return nil, fmt.Errorf("invalid operationId include pattern: %w", err)
+		}
+	}
+	if filter.ExcludeOperationID != "" {
+		if m.excludeOperationID, err = regexp.Compile(filter.ExcludeOperationID); err != nil {
+			return nil, fmt.Errorf("invalid operationId exclude pattern: %w", err)
+		}
+	}
+	for _, glob := range filter.IncludePaths {
+		m.includePaths = append(m.includePaths, globToRegexp(glob))
+	}
+	for _, glob := range filter.ExcludePaths {
+		m.excludePaths = append(m.excludePaths, globToRegexp(glob))
+	}
+
+	return m, nil
+}
+
+// matches reports whether the operation passes the filter.
+func (m *operationMatcher) matches(method, path string, op *openapi3.Operation) bool {
+	f := m.filter
+
+	if f.ExcludeDeprecated && op.Deprecated {
+		return false
+	}
+
+	if len(f.IncludeMethods) > 0 && !containsFold(f.IncludeMethods, method) {
+		return false
+	}
+	if containsFold(f.ExcludeMethods, method) {
+		return false
+	}
+
+	if len(f.IncludeTags) > 0 && !anyContained(op.Tags, f.IncludeTags) {
+		return false
+	}
+	if anyContained(op.Tags, f.ExcludeTags) {
+		return false
+	}
+
+	if m.includeOperationID != nil && !m.includeOperationID.MatchString(op.OperationID) {
+		return false
+	}
+	if m.excludeOperationID != nil && m.excludeOperationID.MatchString(op.OperationID) {
+		return false
+	}
+
+	if len(m.includePaths) > 0 && !matchesAny(m.includePaths, path) {
+		return false
+	}
+	if matchesAny(m.excludePaths, path) {
+		return false
+	}
+
+	return true
+}
+
+// globToRegexp converts a path glob to an anchored regular expression. '**'
+// matches any characters including '/', '*' matches anything but '/' and '?'
+// matches a single character other than '/'.
+func globToRegexp(glob string) *regexp.Regexp {
+	var sb strings.Builder
+	sb.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				sb.WriteString(".*")
+				i++
+			} else {
+				sb.WriteString("[^/]*")
+			}
+		case '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.MustCompile(sb.String())
+}
+
+func matchesAny(patterns []*regexp.Regexp, s string) bool {
+	for _, pattern := range patterns {
+		if pattern.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(list []string, target string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func anyContained(values, list []string) bool {
+	for _, value := range values {
+		if contains(list, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package openapimcp
+
+import (
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOperationMatcher(t *testing.T) {
+	billingGet := &openapi3.Operation{OperationID: "listInvoices", Tags: []string{"Billing"}}
+	billingPost := &openapi3.Operation{OperationID: "createInvoice", Tags: []string{"Billing"}}
+	legacyGet := &openapi3.Operation{OperationID: "listInvoicesV1", Tags: []string{"Billing"}, Deprecated: true}
+	usersGet := &openapi3.Operation{OperationID: "listUsers", Tags: []string{"Users", "Admin"}}
+
+	type operation struct {
+		method, path string
+		op           *openapi3.Operation
+	}
+	operations := []operation{
+		{"GET", "/billing/invoices", billingGet},
+		{"POST", "/billing/invoices", billingPost},
+		{"GET", "/v1/billing/invoices", legacyGet},
+		{"GET", "/admin/users/{id}", usersGet},
+	}
+
+	tests := []struct {
+		name     string
+		filter   OperationFilter
+		expected []string
+	}{
+		{
+			name:     "zero value exposes everything",
+			expected: []string{"listInvoices", "createInvoice", "listInvoicesV1", "listUsers"},
+		},
+		{
+			name:     "read-only billing operations",
+			filter:   OperationFilter{IncludeMethods: []string{"get"}, IncludeTags: []string{"Billing"}},
+			expected: []string{"listInvoices", "listInvoicesV1"},
+		},
+		{
+			name:     "exclude deprecated",
+			filter:   OperationFilter{IncludeTags: []string{"Billing"}, ExcludeDeprecated: true},
+			expected: []string{"listInvoices", "createInvoice"},
+		},
+		{
+			name:     "exclude tags and methods",
+			filter:   OperationFilter{ExcludeTags: []string{"Admin"}, ExcludeMethods: []string{"POST"}},
+			expected: []string{"listInvoices", "listInvoicesV1"},
+		},
+		{
+			name:     "operationId patterns",
+			filter:   OperationFilter{IncludeOperationID: "^list", ExcludeOperationID: "V1$"},
+			expected: []string{"listInvoices", "listUsers"},
+		},
+		{
+			name:     "single segment glob",
+			filter:   OperationFilter{IncludePaths: []string{"/*/invoices"}},
+			expected: []string{"listInvoices", "createInvoice"},
+		},
+		{
+			name:     "multi segment glob",
+			filter:   OperationFilter{IncludePaths: []string{"/**/invoices"}, ExcludePaths: []string{"/v?/**"}},
+			expected: []string{"listInvoices", "createInvoice"},
+		},
+		{
+			name:     "glob with path template",
+			filter:   OperationFilter{IncludePaths: []string{"/admin/users/{id}"}},
+			expected: []string{"listUsers"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matcher, err := newOperationMatcher(tt.filter)
+			require.NoError(t, err)
+
+			matched := []string{}
+			for _, o := range operations {
+				if matcher.matches(o.method, o.path, o.op) {
+					matched = append(matched, o.op.OperationID)
+				}
+			}
+			assert.Equal(t, tt.expected, matched)
+		})
+	}
+}
+
+func TestOperationMatcher_InvalidPattern(t *testing.T) {
+	_, err := BuildMCPServerFromSpec(&openapi3.T{Paths: openapi3.NewPaths()}, &APIConfig{
+		Filter: OperationFilter{IncludeOperationID: "("},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid operationId include pattern")
+}
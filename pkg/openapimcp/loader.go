@@ -14,9 +14,26 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+// LoaderConfig controls how specs are loaded and how their references are resolved.
+type LoaderConfig struct {
+	// AllowExternalRefs enables resolving $refs that point to other files or URLs,
+	// relative to the location of the spec.
+	AllowExternalRefs bool
+	// AllowedRefBases restricts external $refs to these base directories or
+	// http(s) URL prefixes. Defaults to the directory (or host) of the spec itself.
+	AllowedRefBases []string
+}
+
 // LoadSpec loads an OpenAPI 3 specification from a given source (URL or local file path).
-// It supports both JSON and YAML formats.
+// It supports both JSON and YAML formats. Swagger 2.0 documents are converted to OpenAPI 3.
+// External $refs are rejected, use LoadSpecWithConfig to allow them.
 func LoadSpec(source string) (*openapi3.T, error) {
+	return LoadSpecWithConfig(source, LoaderConfig{})
+}
+
+// LoadSpecWithConfig loads an OpenAPI specification like LoadSpec, using config to
+// decide whether and from where external $refs may be resolved.
+func LoadSpecWithConfig(source string, config LoaderConfig) (*openapi3.T, error) {
 	if source == "" {
 		return nil, fmt.Errorf("OpenAPI spec source cannot be empty")
 	}
@@ -67,11 +84,24 @@ func LoadSpec(source string) (*openapi3.T, error) {
 	// Use kin-openapi's loader
 	// The loader can automatically detect JSON or YAML.
 	loader := openapi3.NewLoader()
-	loader.IsExternalRefsAllowed = false // Allow external references if any
+	loader.IsExternalRefsAllowed = config.AllowExternalRefs
+	if config.AllowExternalRefs {
+		allowlist, err := newRefAllowlist(config.AllowedRefBases, baseURI)
+		if err != nil {
+			return nil, err
+		}
+		loader.ReadFromURIFunc = allowlist.readFromURI
+	}
 
 	// kin-openapi's LoadFromData requires a URI to resolve relative references,
 	// even for local files.
-	doc, err := loader.LoadFromDataWithPath(specData, baseURI)
+	var doc *openapi3.T
+	if isSwagger2(specData) {
+		log.Debugf("Detected Swagger 2.0 spec, converting to OpenAPI 3: %s\n", source)
+		doc, err = convertSwagger2(loader, specData, baseURI)
+	} else {
+		doc, err = loader.LoadFromDataWithPath(specData, baseURI)
+	}
 	if err != nil {
 		// Try to provide more specific error if it's a syntax issue
 		if strings.Contains(err.Error(), "yaml:") || strings.Contains(err.Error(), "json:") {
@@ -89,3 +119,94 @@ func LoadSpec(source string) (*openapi3.T, error) {
 	log.Debugf("Successfully loaded and validated OpenAPI spec: %s (Version: %s)\n", doc.Info.Title, doc.Info.Version)
 	return doc, nil
 }
+
+// refAllowlist limits the locations external $refs can be read from.
+type refAllowlist struct {
+	dirs []string   // absolute, cleaned directory paths
+	urls []*url.URL // http(s) URL prefixes
+
+	read openapi3.ReadFromURIFunc
+}
+
+// newRefAllowlist builds an allowlist from the configured bases, falling back to the
+// location of the spec itself (its directory, or its scheme and host) when none are given.
+func newRefAllowlist(bases []string, specURI *url.URL) (*refAllowlist, error) {
+	allowlist := &refAllowlist{}
+
+	// Redirects are checked against the allowlist too, so that an allowed host
+	// cannot hand the request on to one that is not
+	client := &http.Client{CheckRedirect: allowlist.checkRedirect}
+	allowlist.read = openapi3.URIMapCache(openapi3.ReadFromURIs(openapi3.ReadFromHTTP(client), openapi3.ReadFromFile))
+
+	if len(bases) == 0 {
+		if specURI.Scheme == "file" {
+			allowlist.dirs = append(allowlist.dirs, filepath.Dir(filepath.FromSlash(specURI.Path)))
+		} else {
+			allowlist.urls = append(allowlist.urls, &url.URL{Scheme: specURI.Scheme, Host: specURI.Host, Path: "/"})
+		}
+		return allowlist, nil
+	}
+
+	for _, base := range bases {
+		u, err := url.ParseRequestURI(base)
+		if err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+			allowlist.urls = append(allowlist.urls, u)
+			continue
+		}
+		dir, err := filepath.Abs(base)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed ref base %s: %w", base, err)
+		}
+		allowlist.dirs = append(allowlist.dirs, dir)
+	}
+	return allowlist, nil
+}
+
+// readFromURI is an openapi3.ReadFromURIFunc that only reads allowed locations.
+func (a *refAllowlist) readFromURI(loader *openapi3.Loader, location *url.URL) ([]byte, error) {
+	if !a.allows(location) {
+		return nil, fmt.Errorf("external reference %s is outside the allowed ref bases", location)
+	}
+	return a.read(loader, location)
+}
+
+// checkRedirect is the http.Client CheckRedirect policy of external $ref
+// fetches, allowing only redirects to allowed locations
+func (a *refAllowlist) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= 10 {
+		return fmt.Errorf("stopped after 10 redirects")
+	}
+	if !a.allows(req.URL) {
+		return fmt.Errorf("external reference redirected to %s, outside the allowed ref bases", req.URL)
+	}
+	return nil
+}
+
+func (a *refAllowlist) allows(location *url.URL) bool {
+	switch location.Scheme {
+	case "", "file":
+		if location.Host != "" {
+			return false
+		}
+		path, err := filepath.Abs(filepath.FromSlash(location.Path))
+		if err != nil {
+			return false
+		}
+		for _, dir := range a.dirs {
+			if rel, err := filepath.Rel(dir, path); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				return true
+			}
+		}
+	case "http", "https":
+		for _, prefix := range a.urls {
+			if !strings.EqualFold(prefix.Scheme, location.Scheme) || !strings.EqualFold(prefix.Host, location.Host) {
+				continue
+			}
+			prefixPath := strings.TrimSuffix(prefix.Path, "/")
+			if location.Path == prefixPath || strings.HasPrefix(location.Path, prefixPath+"/") {
+				return true
+			}
+		}
+	}
+	return false
+}
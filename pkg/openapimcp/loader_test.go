@@ -0,0 +1,277 @@
+package openapimcp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const swagger2Spec = `
+swagger: "2.0"
+info:
+  title: Legacy Pets
+  version: "1.0"
+host: pets.example.com
+basePath: /v1
+schemes: [https]
+consumes: [application/json]
+produces: [application/json]
+securityDefinitions:
+  api_key:
+    type: apiKey
+    in: header
+    name: X-API-Key
+  basic:
+    type: basic
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      parameters:
+        - name: tags
+          in: query
+          type: array
+          items:
+            type: string
+      responses:
+        "200":
+          description: pets
+          schema:
+            type: array
+            items:
+              $ref: "#/definitions/Pet"
+    post:
+      operationId: createPet
+      security:
+        - api_key: []
+      parameters:
+        - name: pet
+          in: body
+          required: true
+          schema:
+            $ref: "#/definitions/Pet"
+      responses:
+        "201":
+          description: created
+  /pets/{id}/photo:
+    post:
+      operationId: uploadPhoto
+      consumes: [application/x-www-form-urlencoded]
+      parameters:
+        - name: id
+          in: path
+          required: true
+          type: string
+        - name: caption
+          in: formData
+          required: true
+          type: string
+      responses:
+        "204":
+          description: uploaded
+definitions:
+  Pet:
+    type: object
+    required: [name]
+    properties:
+      name:
+        type: string
+`
+
+func writeSpecFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadSpec_Swagger2Conversion(t *testing.T) {
+	doc, err := LoadSpec(writeSpecFile(t, "swagger.yaml", swagger2Spec))
+	require.NoError(t, err)
+
+	require.Len(t, doc.Servers, 1)
+	assert.Equal(t, "https://pets.example.com/v1", doc.Servers[0].URL)
+
+	// body parameters become JSON request bodies with definitions rewritten to components
+	createPet := doc.Paths.Value("/pets").Post
+	require.NotNil(t, createPet.RequestBody)
+	bodySchema := createPet.RequestBody.Value.Content.Get("application/json").Schema
+	assert.Equal(t, "#/components/schemas/Pet", bodySchema.Ref)
+	assert.Contains(t, bodySchema.Value.Properties, "name")
+
+	// formData parameters become a form request body using the operation's consumes
+	uploadPhoto := doc.Paths.Value("/pets/{id}/photo").Post
+	formBody := uploadPhoto.RequestBody.Value.Content.Get("application/x-www-form-urlencoded")
+	require.NotNil(t, formBody)
+	assert.Contains(t, formBody.Schema.Value.Properties, "caption")
+	assert.Equal(t, []string{"caption"}, formBody.Schema.Value.Required)
+
+	// securityDefinitions become security schemes
+	assert.Equal(t, "apiKey", doc.Components.SecuritySchemes["api_key"].Value.Type)
+	assert.Equal(t, "http", doc.Components.SecuritySchemes["basic"].Value.Type)
+	assert.Equal(t, "basic", doc.Components.SecuritySchemes["basic"].Value.Scheme)
+
+	// the default csv collectionFormat is preserved as a non-exploded form parameter
+	tags := doc.Paths.Value("/pets").Get.Parameters.GetByInAndName(openapi3.ParameterInQuery, "tags")
+	require.NotNil(t, tags)
+	assert.Equal(t, openapi3.SerializationForm, tags.Style)
+	require.NotNil(t, tags.Explode)
+	assert.False(t, *tags.Explode)
+}
+
+func TestLoadSpec_Swagger2WithoutHost(t *testing.T) {
+	spec := `
+swagger: "2.0"
+info:
+  title: Legacy Pets
+  version: "1.0"
+basePath: /api/v2
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      responses:
+        "200":
+          description: ok
+`
+	var gotPath string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/docs/swagger.yaml" {
+			_, _ = w.Write([]byte(spec))
+			return
+		}
+		gotPath = r.URL.Path
+	}))
+	defer backend.Close()
+
+	specURL := backend.URL + "/docs/swagger.yaml"
+	doc, err := LoadSpec(specURL)
+	require.NoError(t, err)
+
+	// The base path is kept and resolved against the host serving the spec
+	require.Len(t, doc.Servers, 1)
+	assert.Equal(t, "/api/v2", doc.Servers[0].URL)
+
+	mcpServer, err := BuildMCPServerFromSpec(doc, &APIConfig{SpecURL: specURL})
+	require.NoError(t, err)
+	result := callTool(t, mcpServer, "listPets", map[string]any{})
+	require.False(t, result.IsError, result.Content)
+	assert.Equal(t, "/api/v2/pets", gotPath)
+}
+
+func TestLoadSpec_OpenAPI3Unchanged(t *testing.T) {
+	spec := `{"openapi":"3.0.3","info":{"title":"t","version":"1"},"paths":{"/ping":{"get":{"responses":{"200":{"description":"ok"}}}}}}`
+
+	doc, err := LoadSpec(writeSpecFile(t, "openapi.json", spec))
+	require.NoError(t, err)
+	assert.Equal(t, "3.0.3", doc.OpenAPI)
+	assert.NotNil(t, doc.Paths.Value("/ping").Get)
+}
+
+const multiFileSpec = `
+openapi: 3.0.3
+info:
+  title: Split
+  version: "1"
+paths:
+  /pets:
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                $ref: "schemas/pet.yaml"
+`
+
+func writeMultiFileSpec(t *testing.T) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "api", "schemas"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "api", "schemas", "pet.yaml"), []byte("type: object\nproperties:\n  name:\n    type: string\n"), 0o600))
+	specPath := filepath.Join(dir, "api", "openapi.yaml")
+	require.NoError(t, os.WriteFile(specPath, []byte(multiFileSpec), 0o600))
+	return dir, specPath
+}
+
+func TestLoadSpec_ExternalRefsRejectedByDefault(t *testing.T) {
+	_, specPath := writeMultiFileSpec(t)
+
+	_, err := LoadSpec(specPath)
+	require.Error(t, err)
+}
+
+func TestLoadSpecWithConfig_ExternalRefsResolved(t *testing.T) {
+	_, specPath := writeMultiFileSpec(t)
+
+	doc, err := LoadSpecWithConfig(specPath, LoaderConfig{AllowExternalRefs: true})
+	require.NoError(t, err)
+
+	schema := doc.Paths.Value("/pets").Get.Responses.Status(200).Value.Content.Get("application/json").Schema
+	require.NotNil(t, schema.Value)
+	assert.Contains(t, schema.Value.Properties, "name")
+}
+
+func TestLoadSpecWithConfig_ExternalRefsOutsideAllowlist(t *testing.T) {
+	dir, specPath := writeMultiFileSpec(t)
+	otherDir := filepath.Join(dir, "other")
+	require.NoError(t, os.MkdirAll(otherDir, 0o700))
+
+	_, err := LoadSpecWithConfig(specPath, LoaderConfig{
+		AllowExternalRefs: true,
+		AllowedRefBases:   []string{otherDir},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "outside the allowed ref bases")
+}
+
+func TestRefAllowlist_URLPrefixes(t *testing.T) {
+	allowlist, err := newRefAllowlist([]string{"https://specs.example.com/shared"}, nil)
+	require.NoError(t, err)
+
+	mustParse := func(raw string) *url.URL {
+		u, err := url.Parse(raw)
+		require.NoError(t, err)
+		return u
+	}
+
+	assert.True(t, allowlist.allows(mustParse("https://specs.example.com/shared/pet.yaml")))
+	assert.False(t, allowlist.allows(mustParse("https://specs.example.com/sharedother/pet.yaml")))
+	assert.False(t, allowlist.allows(mustParse("http://specs.example.com/shared/pet.yaml")))
+	assert.False(t, allowlist.allows(mustParse("https://evil.example.com/shared/pet.yaml")))
+	assert.False(t, allowlist.allows(mustParse("file:///etc/passwd")))
+}
+
+func TestLoadSpecWithConfig_ExternalRefRedirectOutsideAllowlist(t *testing.T) {
+	evilHits := 0
+	evil := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		evilHits++
+		_, _ = w.Write([]byte("type: object\n"))
+	}))
+	defer evil.Close()
+
+	allowed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/openapi.yaml":
+			_, _ = w.Write([]byte(multiFileSpec))
+		case "/api/schemas/pet.yaml":
+			http.Redirect(w, r, evil.URL+"/pet.yaml", http.StatusFound)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer allowed.Close()
+
+	_, err := LoadSpecWithConfig(allowed.URL+"/api/openapi.yaml", LoaderConfig{AllowExternalRefs: true})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "outside the allowed ref bases")
+	assert.Zero(t, evilHits)
+}
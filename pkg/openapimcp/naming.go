@@ -0,0 +1,161 @@
+package openapimcp
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// ToolNaming selects how tool names are derived from operations. Names set
+// with x-mcp-name take precedence over every strategy.
+type ToolNaming string
+
+const (
+	// OperationIDNaming names tools after the operationId, falling back to method and path
+	OperationIDNaming ToolNaming = "operation-id"
+	// MethodPathNaming names tools after the method and path, e.g. get_pets_petId
+	MethodPathNaming ToolNaming = "method-path"
+	// TagPrefixedNaming prefixes the operationId based name with the first tag of the operation
+	TagPrefixedNaming ToolNaming = "tag-prefixed"
+)
+
+// maxToolNameLength is the longest tool name MCP clients and LLM providers accept
+const maxToolNameLength = 64
+
+var (
+	invalidToolNameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+	nonWordChars         = regexp.MustCompile(`\W`)
+)
+
+// toolOperation is an operation exposed as a tool, with path-level
+// parameters merged in
+type toolOperation struct {
+	method   string
+	path     string
+	pathItem *openapi3.PathItem
+	op       *openapi3.Operation
+}
+
+// toolNames returns a name matching ^[a-zA-Z0-9_-]{1,64}$ for every
+// operation. Operations sharing a name are told apart by a numeric suffix,
+// assigned in path and method order so that names are stable across runs.
+func (b *MCPServerBuilder) toolNames(operations []toolOperation) []string {
+	names := make([]string, len(operations))
+	taken := map[string]bool{}
+	for i, operation := range operations {
+		names[i] = b.toolName(operation.method, operation.path, operation.op)
+		taken[names[i]] = true
+	}
+
+	order := make([]int, len(operations))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		oi, oj := operations[order[i]], operations[order[j]]
+		if oi.path != oj.path {
+			return oi.path < oj.path
+		}
+		return oi.method < oj.method
+	})
+
+	seen := map[string]bool{}
+	for _, i := range order {
+		name := names[i]
+		if !seen[name] {
+			seen[name] = true
+			continue
+		}
+		for n := 2; ; n++ {
+			suffix := fmt.Sprintf("_%d", n)
+			candidate := truncateToolName(name, maxToolNameLength-len(suffix)) + suffix
+			if !taken[candidate] {
+				names[i] = candidate
+				taken[candidate] = true
+				seen[candidate] = true
+				break
+			}
+		}
+	}
+	return names
+}
+
+// toolName derives the name of a single operation's tool according to the
+// configured naming strategy
+func (b *MCPServerBuilder) toolName(method, path string, op *openapi3.Operation) string {
+	if name := extensionString(op.Extensions, extName); name != "" {
+		return sanitizeToolName(name)
+	}
+
+	switch b.config.ToolNaming {
+	case MethodPathNaming:
+		return methodPathToolName(method, path)
+	case TagPrefixedNaming:
+		name := generateToolName(method, path, op)
+		if len(op.Tags) == 0 {
+			return name
+		}
+		return truncateToolName(sanitizeToolName(op.Tags[0])+"_"+name, maxToolNameLength)
+	default:
+		return generateToolName(method, path, op)
+	}
+}
+
+// generateToolName creates a tool name from the operationId, dropping the
+// path and method suffixes generators such as FastAPI append to it, or from
+// the method and path when the operation has no operationId
+func generateToolName(method, path string, op *openapi3.Operation) string {
+	if name := extensionString(op.Extensions, extName); name != "" {
+		return sanitizeToolName(name)
+	}
+	if op.OperationID == "" {
+		return methodPathToolName(method, path)
+	}
+
+	name := sanitizeToolName(op.OperationID)
+
+	// FastAPI joins the function name, the path and the method, e.g. greet_user_greet__name__get
+	redundant := nonWordChars.ReplaceAllString(path, "_") + "_" + strings.ToLower(method)
+	if trimmed := strings.TrimRight(strings.TrimSuffix(name, redundant), "_-"); trimmed != name && trimmed != "" {
+		name = trimmed
+	}
+
+	if len(name) > maxToolNameLength {
+		if trimmed := strings.TrimSuffix(name, "_"+strings.ToLower(method)); trimmed != "" {
+			name = trimmed
+		}
+	}
+	return truncateToolName(name, maxToolNameLength)
+}
+
+// methodPathToolName names a tool after its method and path, e.g. get_pets_petId
+func methodPathToolName(method, path string) string {
+	cleanPath := strings.NewReplacer("{", "", "}", "").Replace(path)
+	cleanPath = strings.Trim(nonWordChars.ReplaceAllString(cleanPath, "_"), "_")
+	return sanitizeToolName(strings.ToLower(method) + "_" + cleanPath)
+}
+
+// sanitizeToolName replaces the characters tool names cannot contain with
+// underscores and limits the name to maxToolNameLength characters
+func sanitizeToolName(name string) string {
+	name = strings.Trim(invalidToolNameChars.ReplaceAllString(name, "_"), "_")
+	if name == "" {
+		return "tool"
+	}
+	return truncateToolName(name, maxToolNameLength)
+}
+
+// truncateToolName cuts name to at most length characters, without leaving a
+// trailing separator
+func truncateToolName(name string, length int) string {
+	if len(name) <= length {
+		return name
+	}
+	if truncated := strings.TrimRight(name[:length], "_-"); truncated != "" {
+		return truncated
+	}
+	return name[:length]
+}
@@ -0,0 +1,83 @@
+package openapimcp
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var validToolName = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+func TestGenerateToolName(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		operationID string
+		want        string
+	}{
+		{"operation ID", "GET", "/pets", "listPets", "listPets"},
+		{"fastapi suffix", "GET", "/greet/{name}", "greet_user_greet__name__get", "greet_user"},
+		{"invalid characters", "GET", "/pets", "pets.list all/v2", "pets_list_all_v2"},
+		{"method and path", "GET", "/pets/{petId}/toys", "", "get_pets_petId_toys"},
+		{"root path", "GET", "/", "", "get"},
+		{"too long", "POST", "/pets", strings.Repeat("a", 70) + "_post", strings.Repeat("a", 64)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := generateToolName(tt.method, tt.path, &openapi3.Operation{OperationID: tt.operationID})
+			assert.Equal(t, tt.want, got)
+			assert.Regexp(t, validToolName, got)
+		})
+	}
+}
+
+func TestToolNames_Strategies(t *testing.T) {
+	operations := []toolOperation{
+		{method: "GET", path: "/pets/{petId}", op: &openapi3.Operation{OperationID: "getPet", Tags: []string{"pets"}}},
+		{method: "DELETE", path: "/pets/{petId}", op: &openapi3.Operation{OperationID: "deletePet"}},
+	}
+
+	tests := []struct {
+		naming ToolNaming
+		want   []string
+	}{
+		{OperationIDNaming, []string{"getPet", "deletePet"}},
+		{MethodPathNaming, []string{"get_pets_petId", "delete_pets_petId"}},
+		{TagPrefixedNaming, []string{"pets_getPet", "deletePet"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.naming), func(t *testing.T) {
+			builder := NewMCPServerBuilder(&APIConfig{ToolNaming: tt.naming})
+			assert.Equal(t, tt.want, builder.toolNames(operations))
+		})
+	}
+}
+
+func TestToolNames_Unique(t *testing.T) {
+	long := strings.Repeat("x", 64)
+	operations := []toolOperation{
+		{method: "POST", path: "/b", op: &openapi3.Operation{OperationID: "pets"}},
+		{method: "GET", path: "/a", op: &openapi3.Operation{OperationID: "pets"}},
+		{method: "GET", path: "/c", op: &openapi3.Operation{OperationID: "pets_2"}},
+		{method: "GET", path: "/d", op: &openapi3.Operation{OperationID: long}},
+		{method: "GET", path: "/e", op: &openapi3.Operation{OperationID: long}},
+	}
+
+	names := NewMCPServerBuilder(&APIConfig{}).toolNames(operations)
+	// The first operation in path order keeps the name, whatever order the spec is walked in
+	assert.Equal(t, []string{"pets_3", "pets", "pets_2", long, strings.Repeat("x", 62) + "_2"}, names)
+
+	seen := map[string]bool{}
+	for _, name := range names {
+		require.Regexp(t, validToolName, name)
+		require.False(t, seen[name], name)
+		seen[name] = true
+	}
+}
@@ -0,0 +1,205 @@
+package openapimcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// tokenExpiryDelta is subtracted from token lifetimes so tokens are renewed
+// slightly before the authorization server considers them expired.
+const tokenExpiryDelta = 30 * time.Second
+
+// oauth2Token is an access token obtained from a token endpoint.
+type oauth2Token struct {
+	accessToken string
+	expiry      time.Time // zero when the token does not expire
+}
+
+func (t *oauth2Token) valid(now time.Time) bool {
+	return t != nil && t.accessToken != "" && (t.expiry.IsZero() || now.Before(t.expiry))
+}
+
+// oauth2TokenSource obtains access tokens for an oauth2 security scheme using the
+// client-credentials or refresh-token grant, caching them per scope set until expiry.
+type oauth2TokenSource struct {
+	client                *http.Client
+	tokenURL              string
+	refreshURL            string
+	clientCredentialsFlow bool
+
+	clientID     string
+	clientSecret string
+
+	mu           sync.Mutex
+	refreshToken string
+	tokens       map[string]*oauth2Token
+}
+
+// newOAuth2TokenSource creates a token source for the scheme, or returns nil when
+// the configured credentials do not allow obtaining tokens for any of its flows.
+func newOAuth2TokenSource(client *http.Client, scheme *openapi3.SecurityScheme, creds Credentials, resolveURL func(string) string) *oauth2TokenSource {
+	if scheme.Flows == nil {
+		return nil
+	}
+
+	source := &oauth2TokenSource{
+		client:       client,
+		clientID:     creds.ClientID,
+		clientSecret: creds.ClientSecret,
+		refreshToken: creds.RefreshToken,
+		tokens:       map[string]*oauth2Token{},
+	}
+
+	flows := scheme.Flows
+	switch {
+	case flows.ClientCredentials != nil && creds.ClientID != "":
+		source.clientCredentialsFlow = true
+		source.tokenURL = flows.ClientCredentials.TokenURL
+		source.refreshURL = flows.ClientCredentials.RefreshURL
+	case creds.RefreshToken != "":
+		// Any flow with a token endpoint can redeem a refresh token
+		for _, flow := range []*openapi3.OAuthFlow{flows.AuthorizationCode, flows.Password, flows.ClientCredentials} {
+			if flow != nil && flow.TokenURL != "" {
+				source.tokenURL = flow.TokenURL
+				source.refreshURL = flow.RefreshURL
+				break
+			}
+		}
+	}
+	if source.tokenURL == "" {
+		return nil
+	}
+
+	source.tokenURL = resolveURL(source.tokenURL)
+	if source.refreshURL == "" {
+		source.refreshURL = source.tokenURL
+	} else {
+		source.refreshURL = resolveURL(source.refreshURL)
+	}
+	return source
+}
+
+// token returns a valid access token for the scopes, requesting a new one when
+// none is cached or the cached one has expired.
+func (s *oauth2TokenSource) token(ctx context.Context, scopes []string) (string, error) {
+	key := scopeKey(scopes)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if token := s.tokens[key]; token.valid(time.Now()) {
+		return token.accessToken, nil
+	}
+
+	token, err := s.fetch(ctx, scopes)
+	if err != nil {
+		return "", err
+	}
+	s.tokens[key] = token
+	return token.accessToken, nil
+}
+
+// invalidate drops the cached token for the scopes, e.g. after the API rejected it.
+func (s *oauth2TokenSource) invalidate(scopes []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tokens, scopeKey(scopes))
+}
+
+// fetch performs a refresh-token grant when a refresh token is available, falling
+// back to the client-credentials grant if that fails or no refresh token exists.
+func (s *oauth2TokenSource) fetch(ctx context.Context, scopes []string) (*oauth2Token, error) {
+	var refreshErr error
+	if s.refreshToken != "" {
+		form := url.Values{"grant_type": {"refresh_token"}, "refresh_token": {s.refreshToken}}
+		token, err := s.requestToken(ctx, s.refreshURL, form, scopes)
+		if err == nil {
+			return token, nil
+		}
+		refreshErr = err
+		if !s.clientCredentialsFlow {
+			return nil, err
+		}
+	}
+
+	token, err := s.requestToken(ctx, s.tokenURL, url.Values{"grant_type": {"client_credentials"}}, scopes)
+	if err != nil && refreshErr != nil {
+		return nil, fmt.Errorf("%w (refresh token grant: %v)", err, refreshErr)
+	}
+	return token, err
+}
+
+func (s *oauth2TokenSource) requestToken(ctx context.Context, tokenURL string, form url.Values, scopes []string) (*oauth2Token, error) {
+	if len(scopes) > 0 {
+		form.Set("scope", strings.Join(scopes, " "))
+	}
+	if s.clientSecret == "" && s.clientID != "" {
+		form.Set("client_id", s.clientID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if s.clientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(s.clientID), url.QueryEscape(s.clientSecret))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("token request to %s failed: %w", tokenURL, err)
+	}
+
+	//nolint
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token request to %s failed with status %d: %s", tokenURL, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var tokenResp struct {
+		AccessToken  string `json:"access_token"`
+		ExpiresIn    int64  `json:"expires_in"`
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := json.Unmarshal(body, &tokenResp); err != nil {
+		return nil, fmt.Errorf("failed to parse token response: %w", err)
+	}
+	if tokenResp.AccessToken == "" {
+		return nil, fmt.Errorf("token response from %s contains no access_token", tokenURL)
+	}
+
+	// Authorization servers may rotate refresh tokens
+	if tokenResp.RefreshToken != "" {
+		s.refreshToken = tokenResp.RefreshToken
+	}
+
+	token := &oauth2Token{accessToken: tokenResp.AccessToken}
+	if tokenResp.ExpiresIn > 0 {
+		token.expiry = time.Now().Add(time.Duration(tokenResp.ExpiresIn)*time.Second - tokenExpiryDelta)
+	}
+	return token, nil
+}
+
+// scopeKey returns an order independent cache key for a set of scopes.
+func scopeKey(scopes []string) string {
+	sorted := append([]string(nil), scopes...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, " ")
+}
@@ -0,0 +1,147 @@
+package openapimcp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func oauth2Spec(tokenURL string, flows *openapi3.OAuthFlows) *openapi3.T {
+	if flows == nil {
+		flows = &openapi3.OAuthFlows{
+			ClientCredentials: &openapi3.OAuthFlow{TokenURL: tokenURL, Scopes: map[string]string{"billing:read": ""}},
+		}
+	}
+	return &openapi3.T{
+		Components: &openapi3.Components{
+			SecuritySchemes: openapi3.SecuritySchemes{
+				"gateway": {Value: &openapi3.SecurityScheme{Type: "oauth2", Flows: flows}},
+			},
+		},
+		Paths: openapi3.NewPaths(openapi3.WithPath("/invoices", &openapi3.PathItem{
+			Get: &openapi3.Operation{
+				OperationID: "list_invoices",
+				Security:    &openapi3.SecurityRequirements{{"gateway": {"billing:read"}}},
+				Responses:   openapi3.NewResponses(),
+			},
+		})),
+	}
+}
+
+func TestOAuth2_ClientCredentialsTokenIsCached(t *testing.T) {
+	var tokenRequests atomic.Int32
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokenRequests.Add(1)
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+		assert.Equal(t, "billing:read", r.PostForm.Get("scope"))
+		clientID, clientSecret, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "client", clientID)
+		assert.Equal(t, "secret", clientSecret)
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(w, `{"access_token":"token-1","token_type":"Bearer","expires_in":3600}`)
+	}))
+	defer tokenServer.Close()
+
+	var authHeaders []string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeaders = append(authHeaders, r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer backend.Close()
+
+	mcpServer, err := BuildMCPServerFromSpec(oauth2Spec(tokenServer.URL, nil), &APIConfig{
+		BaseURL:     backend.URL,
+		Credentials: map[string]Credentials{"gateway": {ClientID: "client", ClientSecret: "secret"}},
+	})
+	require.NoError(t, err)
+
+	callTool(t, mcpServer, "list_invoices", map[string]any{})
+	callTool(t, mcpServer, "list_invoices", map[string]any{})
+
+	assert.Equal(t, int32(1), tokenRequests.Load())
+	assert.Equal(t, []string{"Bearer token-1", "Bearer token-1"}, authHeaders)
+}
+
+func TestOAuth2_RetriesOnceWithFreshTokenOn401(t *testing.T) {
+	var tokenRequests atomic.Int32
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := tokenRequests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"access_token":"token-%d","expires_in":3600}`, n)
+	}))
+	defer tokenServer.Close()
+
+	var authHeaders []string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeaders = append(authHeaders, r.Header.Get("Authorization"))
+		if r.Header.Get("Authorization") == "Bearer token-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer backend.Close()
+
+	mcpServer, err := BuildMCPServerFromSpec(oauth2Spec(tokenServer.URL, nil), &APIConfig{
+		BaseURL:     backend.URL,
+		Credentials: map[string]Credentials{"gateway": {ClientID: "client", ClientSecret: "secret"}},
+	})
+	require.NoError(t, err)
+
+	result := callTool(t, mcpServer, "list_invoices", map[string]any{})
+	require.Len(t, result.Content, 1)
+
+	assert.Equal(t, int32(2), tokenRequests.Load())
+	assert.Equal(t, []string{"Bearer token-1", "Bearer token-2"}, authHeaders)
+}
+
+func TestOAuth2_RefreshTokenGrant(t *testing.T) {
+	var refreshTokens []string
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "refresh_token", r.PostForm.Get("grant_type"))
+		assert.Equal(t, "public-client", r.PostForm.Get("client_id"))
+		refreshTokens = append(refreshTokens, r.PostForm.Get("refresh_token"))
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"access_token":"access-%d","refresh_token":"rotated-%d"}`, len(refreshTokens), len(refreshTokens))
+	}))
+	defer tokenServer.Close()
+
+	flows := &openapi3.OAuthFlows{
+		AuthorizationCode: &openapi3.OAuthFlow{
+			AuthorizationURL: "https://auth.example.com/authorize",
+			TokenURL:         tokenServer.URL,
+		},
+	}
+	source := newOAuth2TokenSource(http.DefaultClient, oauth2Spec("", flows).Components.SecuritySchemes["gateway"].Value,
+		Credentials{ClientID: "public-client", RefreshToken: "initial"}, func(u string) string { return u })
+	require.NotNil(t, source)
+
+	token, err := source.token(context.Background(), []string{"billing:read"})
+	require.NoError(t, err)
+	assert.Equal(t, "access-1", token)
+
+	source.invalidate([]string{"billing:read"})
+	token, err = source.token(context.Background(), []string{"billing:read"})
+	require.NoError(t, err)
+	assert.Equal(t, "access-2", token)
+
+	// the rotated refresh token is used for the second grant
+	assert.Equal(t, []string{"initial", "rotated-1"}, refreshTokens)
+}
+
+func TestOAuth2_NoTokenSourceWithoutCredentials(t *testing.T) {
+	scheme := oauth2Spec("https://auth.example.com/token", nil).Components.SecuritySchemes["gateway"].Value
+	assert.Nil(t, newOAuth2TokenSource(http.DefaultClient, scheme, Credentials{}, func(u string) string { return u }))
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"os"
+	"time"
 
 	"github.com/mark3labs/mcp-go/server"
 )
@@ -11,14 +13,42 @@ import (
 type ServerMode string
 
 const (
-	StdIO ServerMode = "stdio"
-	SSE   ServerMode = "sse"
+	StdIO          ServerMode = "stdio"
+	SSE            ServerMode = "sse"
+	StreamableHTTP ServerMode = "streamable-http"
 )
 
 // GeneratorConfig holds the configuration for generating and running the MCP server.
 type GeneratorConfig struct {
 	SpecSource string     // URL or file path to the OpenAPI spec
-	ServerMode ServerMode // server.ModeStdIO or server.ModeSSE
+	ServerMode ServerMode // StdIO, SSE or StreamableHTTP
+
+	AllowExternalRefs bool     // Resolve $refs to other files or URLs
+	AllowedRefBases   []string // Directories or URL prefixes external $refs may be loaded from
+
+	BaseURL         string            // Overrides the API base URL taken from the spec servers
+	ServerIndex     int               // Index of the spec server to use
+	ServerVariables map[string]string // Overrides for server variable defaults
+
+	CredentialsFile string            // JSON/YAML file with credentials keyed by security scheme name
+	Headers         map[string]string // Extra headers sent with every API request
+
+	Filter OperationFilter // Selects the operations exposed as tools
+
+	ValidateResponses bool     // Report API responses that do not match the spec
+	SessionCookies    bool     // Keep cookies set by the API in a jar per MCP session
+	FileRoots         []string // Directories file:// arguments of binary fields may be read from
+
+	ToolNaming     ToolNaming            // How tool names are derived from operations
+	BodyCollisions BodyCollisionStrategy // How body fields named like a parameter are exposed
+
+	DescriptionTemplateFile string // File with a text/template for tool descriptions
+	DescriptionMaxLength    int    // Longest tool description in characters, zero means no limit
+
+	// HTTP transport settings, ignored in stdio mode
+	ListenAddr string        // Address to listen on, defaults to DefaultListenAddr
+	BasePath   string        // Prefix of the SSE endpoints, or the streamable HTTP endpoint itself
+	KeepAlive  time.Duration // Interval between keep-alive pings, zero disables them
 }
 
 // RunFromSpec loads an OpenAPI spec, builds an MCP server, and starts it.
@@ -28,15 +58,55 @@ func RunFromSpec(config GeneratorConfig) error {
 	}
 
 	// Load the OpenAPI spec
-	openapiSpec, err := LoadSpec(config.SpecSource)
+	openapiSpec, err := LoadSpecWithConfig(config.SpecSource, LoaderConfig{
+		AllowExternalRefs: config.AllowExternalRefs,
+		AllowedRefBases:   config.AllowedRefBases,
+	})
 	if err != nil {
 		return fmt.Errorf("failed to load OpenAPI spec: %w", err)
 	}
 
+	credentials, err := LoadCredentials(config.CredentialsFile, openapiSpec)
+	if err != nil {
+		return fmt.Errorf("failed to load credentials: %w", err)
+	}
+
+	descriptionTemplate := ""
+	if config.DescriptionTemplateFile != "" {
+		data, err := os.ReadFile(config.DescriptionTemplateFile)
+		if err != nil {
+			return fmt.Errorf("failed to read description template: %w", err)
+		}
+		descriptionTemplate = string(data)
+	}
+
+	headers := make(map[string]string, len(config.Headers))
+	for key, value := range config.Headers {
+		headers[key] = value
+	}
+
 	serverCfg := &APIConfig{
-		BaseURL:    getBaseURLFromSpecSource(config.SpecSource),
+		BaseURL:    config.BaseURL,
 		HTTPClient: &http.Client{},
-		Headers:    make(map[string]string),
+		Headers:    headers,
+
+		SpecURL:         getSpecURLFromSpecSource(config.SpecSource),
+		ServerIndex:     config.ServerIndex,
+		ServerVariables: config.ServerVariables,
+
+		Credentials: credentials,
+
+		Filter: config.Filter,
+
+		ValidateResponses: config.ValidateResponses,
+		SessionCookies:    config.SessionCookies,
+		FileRoots:         config.FileRoots,
+
+		ToolNaming:     config.ToolNaming,
+		BodyCollisions: config.BodyCollisions,
+
+		DescriptionTemplate:  descriptionTemplate,
+		DescriptionMaxLength: config.DescriptionMaxLength,
 	}
 
 	// Build the MCP server from the spec and config
@@ -46,21 +116,29 @@ func RunFromSpec(config GeneratorConfig) error {
 	}
 
 	// Start the MCP server
-	// log.Printf("Starting MCP server '%s' in %s mode...", mcpServer.Name(), mcpServer.Mode())
-	if err := server.ServeStdio(mcpServer); err != nil {
+	switch config.ServerMode {
+	case StdIO, "":
+		err = server.ServeStdio(mcpServer)
+	case SSE:
+		err = serveSSE(mcpServer, config)
+	case StreamableHTTP:
+		err = serveStreamableHTTP(mcpServer, config)
+	default:
+		return fmt.Errorf("unsupported server mode: %s", config.ServerMode)
+	}
+	if err != nil {
 		return fmt.Errorf("MCP server failed to start or exited with error: %w", err)
 	}
 
-	// log.Printf("MCP server '%s' has stopped.", mcpServer.Name)
 	return nil
-
 }
 
-func getBaseURLFromSpecSource(specSource string) string {
-	var baseURL string
+// getSpecURLFromSpecSource returns the spec source if it is an http(s) URL, or an
+// empty string for local files.
+func getSpecURLFromSpecSource(specSource string) string {
 	u, urlErr := url.ParseRequestURI(specSource)
 	if urlErr == nil && (u.Scheme == "http" || u.Scheme == "https") {
-		baseURL = fmt.Sprintf("%s://%s", u.Scheme, u.Host)
+		return specSource
 	}
-	return baseURL
+	return ""
 }
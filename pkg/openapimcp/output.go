@@ -0,0 +1,241 @@
+package openapimcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// resultProperty is the structured content field holding a response body that
+// is not a JSON object, as MCP requires structured content to be an object.
+const resultProperty = "result"
+
+// apiResponse is the response of an upstream API call
+type apiResponse struct {
+	StatusCode int
+	Status     string
+	Header     http.Header
+	Body       []byte
+}
+
+// text formats the response with its status information
+func (r *apiResponse) text() string {
+	result := fmt.Sprintf("Status: %d %s\n", r.StatusCode, r.Status)
+
+	if len(r.Body) > 0 {
+		// Try to pretty-print JSON
+		var jsonObj any
+		if err := json.Unmarshal(r.Body, &jsonObj); err == nil {
+			if prettyJSON, err := json.MarshalIndent(jsonObj, "", "  "); err == nil {
+				result += "Response:\n" + string(prettyJSON)
+			} else {
+				result += "Response:\n" + string(r.Body)
+			}
+		} else {
+			result += "Response:\n" + string(r.Body)
+		}
+	}
+
+	return result
+}
+
+// toolOutput describes the structured output of a tool derived from the
+// operation's success response
+type toolOutput struct {
+	schema   map[string]any
+	wrapped  bool // the response body is nested under resultProperty
+	nullable bool // a success response may have no body, reported as null
+
+	source *openapi3.SchemaRef // response schema the structured content is validated against
+}
+
+// newToolOutput derives the tool output from the JSON schemas of the
+// operation's 2xx responses. It returns nil, leaving the output undeclared,
+// when a 2xx response is not JSON or the responses disagree on the schema,
+// since every successful result must conform to the declared schema.
+func newToolOutput(op *openapi3.Operation) *toolOutput {
+	var schema map[string]any
+	var source *openapi3.SchemaRef
+	var encoded []byte
+	emptyBody := false
+	for _, response := range successResponses(op) {
+		if len(response.Content) == 0 {
+			emptyBody = true
+			continue
+		}
+		schemaRef := jsonSchema(response.Content)
+		if schemaRef == nil || !onlyJSON(response.Content) {
+			return nil
+		}
+
+		converted := convertResponseSchemaToMCP(schemaRef)
+		data, err := json.Marshal(converted)
+		if err != nil || (encoded != nil && !bytes.Equal(data, encoded)) {
+			return nil
+		}
+		schema, source, encoded = converted, schemaRef, data
+	}
+	if schema == nil {
+		return nil
+	}
+
+	if schema["type"] == "object" && !emptyBody {
+		return &toolOutput{schema: schema, source: source}
+	}
+
+	// Responses without a body are reported as a null result
+	if emptyBody {
+		schema = allowNull(schema)
+	}
+	return &toolOutput{
+		schema: map[string]any{
+			"type":       "object",
+			"properties": map[string]any{resultProperty: schema},
+			"required":   []string{resultProperty},
+		},
+		wrapped:  true,
+		nullable: emptyBody,
+		source:   source,
+	}
+}
+
+// structuredContent returns the structured content for a successful
+// response, or nil when the response does not conform to the output schema.
+func (o *toolOutput) structuredContent(resp *apiResponse) any {
+	if o == nil || resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil
+	}
+
+	var content any
+	if len(resp.Body) > 0 {
+		if err := json.Unmarshal(resp.Body, &content); err != nil {
+			return nil
+		}
+		if o.source != nil && o.source.Value != nil {
+			if err := o.source.Value.VisitJSON(content, openapi3.VisitAsResponse()); err != nil {
+				return nil
+			}
+		}
+	} else if !o.nullable {
+		return nil
+	}
+
+	if o.wrapped {
+		return map[string]any{resultProperty: content}
+	}
+	if _, ok := content.(map[string]any); !ok {
+		return nil
+	}
+	return content
+}
+
+// toolResult converts an API response into a tool result carrying both the
+// formatted text and, when the tool declares an output, the structured
+// content. 4xx and 5xx responses are reported as tool errors, and so are
+// successful responses that cannot be represented in the declared output.
+func (o *toolOutput) toolResult(op *openapi3.Operation, resp *apiResponse) *mcp.CallToolResult {
+	if resp.StatusCode >= http.StatusBadRequest {
+		return newAPIError(op, resp).toolResult()
+	}
+	if o == nil {
+		return mcp.NewToolResultText(resp.text())
+	}
+
+	content := o.structuredContent(resp)
+	if content == nil {
+		return mcp.NewToolResultError(resp.text() + "\n\nThe response does not match the output schema of the tool.")
+	}
+	result := mcp.NewToolResultText(resp.text())
+	result.StructuredContent = content
+	return result
+}
+
+// successResponses returns the operation's 2xx responses, explicit status
+// codes in ascending order followed by the 2XX range
+func successResponses(op *openapi3.Operation) []*openapi3.Response {
+	if op.Responses == nil {
+		return nil
+	}
+
+	codes := []string{}
+	for code := range op.Responses.Map() {
+		if len(code) == 3 && code[0] == '2' && code[1:] != "XX" {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+	codes = append(codes, "2XX")
+
+	responses := []*openapi3.Response{}
+	for _, code := range codes {
+		if responseRef := op.Responses.Value(code); responseRef != nil && responseRef.Value != nil {
+			responses = append(responses, responseRef.Value)
+		}
+	}
+	return responses
+}
+
+// successResponseSchema returns the JSON schema of the lowest 2xx response of
+// the operation, falling back to the 2XX range.
+func successResponseSchema(op *openapi3.Operation) *openapi3.SchemaRef {
+	for _, response := range successResponses(op) {
+		if schema := jsonSchema(response.Content); schema != nil {
+			return schema
+		}
+	}
+	return nil
+}
+
+// jsonSchema returns the schema of the first JSON media type in content
+func jsonSchema(content openapi3.Content) *openapi3.SchemaRef {
+	mediaTypes := make([]string, 0, len(content))
+	for mediaType := range content {
+		mediaTypes = append(mediaTypes, mediaType)
+	}
+	sort.Strings(mediaTypes)
+
+	for _, mediaType := range mediaTypes {
+		if strings.Contains(mediaType, "json") && content[mediaType].Schema != nil {
+			return content[mediaType].Schema
+		}
+	}
+	return nil
+}
+
+// onlyJSON reports whether every media type of content is JSON
+func onlyJSON(content openapi3.Content) bool {
+	for mediaType := range content {
+		if !strings.Contains(mediaType, "json") {
+			return false
+		}
+	}
+	return true
+}
+
+// allowNull extends a converted schema to also accept null
+func allowNull(schema map[string]any) map[string]any {
+	switch t := schema["type"].(type) {
+	case string:
+		if t == "null" {
+			return schema
+		}
+		schema["type"] = []string{t, "null"}
+	case []string:
+		if !slices.Contains(t, "null") {
+			schema["type"] = append(slices.Clone(t), "null")
+		}
+	default:
+		return map[string]any{"anyOf": []any{schema, map[string]any{"type": "null"}}}
+	}
+	if enum, ok := schema["enum"].([]any); ok && !slices.Contains(enum, nil) {
+		schema["enum"] = append(slices.Clone(enum), nil)
+	}
+	return schema
+}
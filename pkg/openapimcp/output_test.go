@@ -0,0 +1,207 @@
+package openapimcp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func jsonResponses(code string, schema *openapi3.Schema) *openapi3.Responses {
+	return openapi3.NewResponses(openapi3.WithName(code, openapi3.NewResponse().
+		WithDescription("OK").
+		WithJSONSchema(schema)))
+}
+
+func TestNewToolOutput(t *testing.T) {
+	t.Run("object response is used as is", func(t *testing.T) {
+		op := &openapi3.Operation{Responses: jsonResponses("200", openapi3.NewObjectSchema().
+			WithProperty("id", openapi3.NewIntegerSchema()))}
+
+		output := newToolOutput(op)
+		require.NotNil(t, output)
+		assert.False(t, output.wrapped)
+		assert.Equal(t, "object", output.schema["type"])
+		assert.Contains(t, output.schema["properties"], "id")
+	})
+
+	t.Run("array response is wrapped", func(t *testing.T) {
+		op := &openapi3.Operation{Responses: jsonResponses("201", openapi3.NewArraySchema().
+			WithItems(openapi3.NewStringSchema()))}
+
+		output := newToolOutput(op)
+		require.NotNil(t, output)
+		assert.True(t, output.wrapped)
+		assert.Equal(t, []string{"result"}, output.schema["required"])
+		result := output.schema["properties"].(map[string]any)["result"].(map[string]any)
+		assert.Equal(t, "array", result["type"])
+	})
+
+	t.Run("untyped response accepts any value", func(t *testing.T) {
+		// FastAPI declares schema: {} for endpoints without a response model
+		output := newToolOutput(&openapi3.Operation{Responses: jsonResponses("200", &openapi3.Schema{})})
+		require.NotNil(t, output)
+		assert.True(t, output.wrapped)
+		assert.Equal(t, map[string]any{}, output.schema["properties"].(map[string]any)["result"])
+	})
+
+	t.Run("response without body makes the result nullable", func(t *testing.T) {
+		responses := jsonResponses("200", openapi3.NewObjectSchema().WithProperty("id", openapi3.NewIntegerSchema()))
+		responses.Set("204", &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("No Content")})
+
+		output := newToolOutput(&openapi3.Operation{Responses: responses})
+		require.NotNil(t, output)
+		assert.True(t, output.wrapped)
+		result := output.schema["properties"].(map[string]any)["result"].(map[string]any)
+		assert.Equal(t, []string{"object", "null"}, result["type"])
+	})
+
+	t.Run("non-JSON or conflicting success responses are not declared", func(t *testing.T) {
+		text := jsonResponses("200", openapi3.NewObjectSchema())
+		text.Set("201", &openapi3.ResponseRef{Value: openapi3.NewResponse().WithContent(openapi3.NewContentWithSchema(openapi3.NewStringSchema(), []string{"text/plain"}))})
+		assert.Nil(t, newToolOutput(&openapi3.Operation{Responses: text}))
+
+		conflicting := jsonResponses("200", openapi3.NewObjectSchema())
+		conflicting.Set("201", &openapi3.ResponseRef{Value: openapi3.NewResponse().WithJSONSchema(openapi3.NewArraySchema())})
+		assert.Nil(t, newToolOutput(&openapi3.Operation{Responses: conflicting}))
+	})
+
+	t.Run("no success response", func(t *testing.T) {
+		op := &openapi3.Operation{Responses: jsonResponses("404", openapi3.NewObjectSchema())}
+		assert.Nil(t, newToolOutput(op))
+	})
+}
+
+func TestBuildMCPServerFromSpec_StructuredOutput(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/pets/1":
+			_, _ = w.Write([]byte(`{"id":1,"name":"Rex"}`))
+		case "/pets":
+			_, _ = w.Write([]byte(`[{"id":1,"name":"Rex"}]`))
+		case "/greet":
+			_, _ = w.Write([]byte(`{"message":"Hello"}`))
+		}
+	}))
+	defer backend.Close()
+
+	pet := openapi3.NewObjectSchema().
+		WithProperty("id", openapi3.NewIntegerSchema()).
+		WithProperty("name", openapi3.NewStringSchema())
+	spec := &openapi3.T{Paths: openapi3.NewPaths(
+		openapi3.WithPath("/pets/1", &openapi3.PathItem{
+			Get: &openapi3.Operation{OperationID: "getPet", Responses: jsonResponses("200", pet)},
+		}),
+		openapi3.WithPath("/pets", &openapi3.PathItem{
+			Get: &openapi3.Operation{OperationID: "listPets", Responses: jsonResponses("200", openapi3.NewArraySchema().WithItems(pet))},
+		}),
+		openapi3.WithPath("/greet", &openapi3.PathItem{
+			Get: &openapi3.Operation{OperationID: "greet", Responses: jsonResponses("200", &openapi3.Schema{})},
+		}),
+	)}
+
+	mcpServer, err := BuildMCPServerFromSpec(spec, &APIConfig{BaseURL: backend.URL})
+	require.NoError(t, err)
+
+	for _, tool := range listTools(t, mcpServer) {
+		var outputSchema map[string]any
+		require.NoError(t, json.Unmarshal(tool.RawOutputSchema, &outputSchema), tool.Name)
+		assert.Equal(t, "object", outputSchema["type"], tool.Name)
+		if tool.Name == "greet" {
+			assert.Equal(t, map[string]any{"result": map[string]any{}}, outputSchema["properties"])
+		}
+	}
+
+	result := callTool(t, mcpServer, "getPet", map[string]any{})
+	assert.Equal(t, map[string]any{"id": float64(1), "name": "Rex"}, result.StructuredContent)
+	require.Len(t, result.Content, 1)
+
+	result = callTool(t, mcpServer, "listPets", map[string]any{})
+	assert.Equal(t, map[string]any{"result": []any{map[string]any{"id": float64(1), "name": "Rex"}}}, result.StructuredContent)
+
+	result = callTool(t, mcpServer, "greet", map[string]any{})
+	require.False(t, result.IsError)
+	assert.Equal(t, map[string]any{"result": map[string]any{"message": "Hello"}}, result.StructuredContent)
+}
+
+func TestBuildMCPServerFromSpec_StructuredOutputWithoutBody(t *testing.T) {
+	status := http.StatusNoContent
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer backend.Close()
+
+	pet := openapi3.NewObjectSchema().WithProperty("id", openapi3.NewIntegerSchema())
+	updateResponses := jsonResponses("200", pet)
+	updateResponses.Set("204", &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("No Content")})
+	spec := &openapi3.T{Paths: openapi3.NewPaths(openapi3.WithPath("/pets/1", &openapi3.PathItem{
+		Get: &openapi3.Operation{OperationID: "getPet", Responses: jsonResponses("200", pet)},
+		Put: &openapi3.Operation{OperationID: "updatePet", Responses: updateResponses},
+	}))}
+
+	mcpServer, err := BuildMCPServerFromSpec(spec, &APIConfig{BaseURL: backend.URL})
+	require.NoError(t, err)
+
+	// A declared 204 and an empty 200 body are both reported as a null result
+	for _, status = range []int{http.StatusNoContent, http.StatusOK} {
+		result := callTool(t, mcpServer, "updatePet", map[string]any{})
+		assert.False(t, result.IsError, status)
+		assert.Equal(t, map[string]any{"result": nil}, result.StructuredContent, status)
+	}
+
+	// A body the declared object output cannot represent is not passed off as a success
+	result := callTool(t, mcpServer, "getPet", map[string]any{})
+	assert.True(t, result.IsError)
+	assert.Nil(t, result.StructuredContent)
+}
+
+func TestBuildMCPServerFromSpec_StructuredOutputValidation(t *testing.T) {
+	var body string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+	defer backend.Close()
+
+	pet := openapi3.NewObjectSchema().
+		WithProperty("id", openapi3.NewIntegerSchema()).
+		WithProperty("name", openapi3.NewStringSchema())
+	pet.Required = []string{"id"}
+	spec := &openapi3.T{Paths: openapi3.NewPaths(openapi3.WithPath("/pets", &openapi3.PathItem{
+		Get:  &openapi3.Operation{OperationID: "listPets", Responses: jsonResponses("200", openapi3.NewArraySchema().WithItems(pet))},
+		Post: &openapi3.Operation{OperationID: "createPet", Responses: jsonResponses("201", pet)},
+	}))}
+
+	mcpServer, err := BuildMCPServerFromSpec(spec, &APIConfig{BaseURL: backend.URL})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		tool string
+		body string
+	}{
+		{"missing required property", "createPet", `{"name":"Rex"}`},
+		{"wrong property type", "createPet", `{"id":"1"}`},
+		{"wrong item type", "listPets", `[{"id":1},"Rex"]`},
+		{"undeclared empty body", "listPets", ``},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body = tt.body
+			result := callTool(t, mcpServer, tt.tool, map[string]any{})
+			assert.True(t, result.IsError)
+			assert.Nil(t, result.StructuredContent)
+		})
+	}
+
+	body = `{"id":1}`
+	result := callTool(t, mcpServer, "createPet", map[string]any{})
+	require.False(t, result.IsError)
+	assert.Equal(t, map[string]any{"id": float64(1)}, result.StructuredContent)
+}
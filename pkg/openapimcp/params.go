@@ -0,0 +1,252 @@
+package openapimcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// reservedChars are the RFC 3986 reserved characters a query parameter with
+// allowReserved may carry unencoded
+const reservedChars = ":/?#[]@!$&'()*+,;="
+
+// paramValue is a tool argument broken down into the parts the OpenAPI
+// serialization styles are defined on
+type paramValue struct {
+	scalar string
+	items  []string    // set for arrays
+	props  [][2]string // set for objects, sorted by property name
+}
+
+func newParamValue(value any) paramValue {
+	switch v := value.(type) {
+	case []any:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, formatScalar(item))
+		}
+		return paramValue{items: items}
+	case map[string]any:
+		props := make([][2]string, 0, len(v))
+		for _, key := range sortedKeys(v) {
+			props = append(props, [2]string{key, formatScalar(v[key])})
+		}
+		return paramValue{props: props}
+	default:
+		return paramValue{scalar: formatScalar(v)}
+	}
+}
+
+// formatScalar renders a primitive value, falling back to JSON for nested
+// structures that the serialization styles cannot express
+func formatScalar(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case []any, map[string]any:
+		if data, err := json.Marshal(v); err == nil {
+			return string(data)
+		}
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// contentValue serializes the value of a parameter described by a content map
+// rather than a schema
+func contentValue(param *openapi3.Parameter, value any) any {
+	if param.Schema != nil || len(param.Content) == 0 {
+		return value
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	return string(data)
+}
+
+// parameterSchema returns the schema of a parameter, taken from its content
+// map when the parameter has no schema
+func parameterSchema(param *openapi3.Parameter) *openapi3.SchemaRef {
+	if param.Schema != nil {
+		return param.Schema
+	}
+	mediaTypes := make([]string, 0, len(param.Content))
+	for mediaType := range param.Content {
+		mediaTypes = append(mediaTypes, mediaType)
+	}
+	sort.Strings(mediaTypes)
+
+	for _, mediaType := range mediaTypes {
+		if media := param.Content[mediaType]; media != nil && media.Schema != nil {
+			return media.Schema
+		}
+	}
+	return nil
+}
+
+// parameterExampleValues returns the values of the parameter's named
+// examples, ordered by example name
+func parameterExampleValues(param *openapi3.Parameter) []any {
+	names := make([]string, 0, len(param.Examples))
+	for name := range param.Examples {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	values := []any{}
+	for _, name := range names {
+		if exampleRef := param.Examples[name]; exampleRef != nil && exampleRef.Value != nil && exampleRef.Value.Value != nil {
+			values = append(values, exampleRef.Value.Value)
+		}
+	}
+	return values
+}
+
+// serializationMethod returns the style and explode settings of the parameter,
+// applying the defaults of its location
+func serializationMethod(param *openapi3.Parameter) *openapi3.SerializationMethod {
+	method, err := param.SerializationMethod()
+	if err != nil {
+		return &openapi3.SerializationMethod{Style: openapi3.SerializationSimple}
+	}
+	return method
+}
+
+// serializePathParameter renders a path parameter value as the text replacing
+// its template expression, percent-encoding every component
+func serializePathParameter(param *openapi3.Parameter, value any) string {
+	value = contentValue(param, value)
+	method := serializationMethod(param)
+	v := newParamValue(value)
+	name := url.PathEscape(param.Name)
+
+	switch method.Style {
+	case openapi3.SerializationLabel:
+		sep := ","
+		if method.Explode {
+			sep = "."
+		}
+		return "." + joinValue(v, sep, method.Explode, url.PathEscape)
+	case openapi3.SerializationMatrix:
+		switch {
+		case v.items != nil && method.Explode:
+			parts := make([]string, 0, len(v.items))
+			for _, item := range v.items {
+				parts = append(parts, ";"+name+"="+url.PathEscape(item))
+			}
+			return strings.Join(parts, "")
+		case v.props != nil && method.Explode:
+			return ";" + joinValue(v, ";", true, url.PathEscape)
+		default:
+			return ";" + name + "=" + joinValue(v, ",", false, url.PathEscape)
+		}
+	default:
+		return joinValue(v, ",", method.Explode, url.PathEscape)
+	}
+}
+
+// serializeQueryParameter renders a query parameter value as encoded
+// name=value pairs
+func serializeQueryParameter(param *openapi3.Parameter, value any) []string {
+	value = contentValue(param, value)
+	method := serializationMethod(param)
+	v := newParamValue(value)
+
+	escape := url.QueryEscape
+	if param.AllowReserved {
+		escape = escapeAllowReserved
+	}
+	name := url.QueryEscape(param.Name)
+
+	switch {
+	case method.Style == openapi3.SerializationDeepObject && v.props != nil:
+		pairs := make([]string, 0, len(v.props))
+		for _, prop := range v.props {
+			pairs = append(pairs, name+"["+url.QueryEscape(prop[0])+"]="+escape(prop[1]))
+		}
+		return pairs
+	case method.Explode && v.items != nil:
+		pairs := make([]string, 0, len(v.items))
+		for _, item := range v.items {
+			pairs = append(pairs, name+"="+escape(item))
+		}
+		return pairs
+	case method.Explode && v.props != nil:
+		pairs := make([]string, 0, len(v.props))
+		for _, prop := range v.props {
+			pairs = append(pairs, url.QueryEscape(prop[0])+"="+escape(prop[1]))
+		}
+		return pairs
+	case method.Style == openapi3.SerializationSpaceDelimited:
+		return []string{name + "=" + joinValue(v, "%20", false, escape)}
+	case method.Style == openapi3.SerializationPipeDelimited:
+		return []string{name + "=" + joinValue(v, "%7C", false, escape)}
+	default:
+		return []string{name + "=" + joinValue(v, ",", false, escape)}
+	}
+}
+
+// serializeHeaderParameter renders a header parameter value in the simple style
+func serializeHeaderParameter(param *openapi3.Parameter, value any) string {
+	value = contentValue(param, value)
+	method := serializationMethod(param)
+	return joinValue(newParamValue(value), ",", method.Explode, func(s string) string { return s })
+}
+
+// serializeCookieParameter renders a cookie parameter value in the form
+// style, percent-encoding the characters a cookie value cannot carry
+func serializeCookieParameter(param *openapi3.Parameter, value any) string {
+	value = contentValue(param, value)
+	return joinValue(newParamValue(value), ",", false, escapeCookieValue)
+}
+
+// joinValue joins the escaped components of a value with sep. Exploded
+// object properties are rendered as key=value pairs, otherwise keys and
+// values alternate.
+func joinValue(v paramValue, sep string, explode bool, escape func(string) string) string {
+	switch {
+	case v.items != nil:
+		parts := make([]string, 0, len(v.items))
+		for _, item := range v.items {
+			parts = append(parts, escape(item))
+		}
+		return strings.Join(parts, sep)
+	case v.props != nil:
+		parts := make([]string, 0, 2*len(v.props))
+		for _, prop := range v.props {
+			if explode {
+				parts = append(parts, escape(prop[0])+"="+escape(prop[1]))
+			} else {
+				parts = append(parts, escape(prop[0]), escape(prop[1]))
+			}
+		}
+		return strings.Join(parts, sep)
+	default:
+		return escape(v.scalar)
+	}
+}
+
+// escapeAllowReserved percent-encodes a query value, leaving the RFC 3986
+// reserved characters intact
+func escapeAllowReserved(s string) string {
+	var b strings.Builder
+	for _, part := range strings.SplitAfter(s, "") {
+		if strings.Contains(reservedChars, part) {
+			b.WriteString(part)
+		} else {
+			b.WriteString(url.QueryEscape(part))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,124 @@
+package openapimcp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	primitiveValue = float64(5)
+	arrayValue     = []any{float64(3), float64(4), float64(5)}
+	objectValue    = map[string]any{"role": "admin", "firstName": "Alex"}
+)
+
+func styledParameter(in, style string, explode bool) *openapi3.Parameter {
+	return &openapi3.Parameter{Name: "id", In: in, Style: style, Explode: &explode}
+}
+
+func TestSerializePathParameter(t *testing.T) {
+	tests := []struct {
+		style     string
+		explode   bool
+		primitive string
+		array     string
+		object    string
+	}{
+		{"simple", false, "5", "3,4,5", "firstName,Alex,role,admin"},
+		{"simple", true, "5", "3,4,5", "firstName=Alex,role=admin"},
+		{"label", false, ".5", ".3,4,5", ".firstName,Alex,role,admin"},
+		{"label", true, ".5", ".3.4.5", ".firstName=Alex.role=admin"},
+		{"matrix", false, ";id=5", ";id=3,4,5", ";id=firstName,Alex,role,admin"},
+		{"matrix", true, ";id=5", ";id=3;id=4;id=5", ";firstName=Alex;role=admin"},
+	}
+
+	for _, tt := range tests {
+		param := styledParameter("path", tt.style, tt.explode)
+		assert.Equal(t, tt.primitive, serializePathParameter(param, primitiveValue), "%s explode=%v", tt.style, tt.explode)
+		assert.Equal(t, tt.array, serializePathParameter(param, arrayValue), "%s explode=%v", tt.style, tt.explode)
+		assert.Equal(t, tt.object, serializePathParameter(param, objectValue), "%s explode=%v", tt.style, tt.explode)
+	}
+
+	assert.Equal(t, "a%2Fb%3Fc%20d", serializePathParameter(&openapi3.Parameter{Name: "id", In: "path"}, "a/b?c d"))
+}
+
+func TestSerializeQueryParameter(t *testing.T) {
+	tests := []struct {
+		style   string
+		explode bool
+		array   []string
+		object  []string
+	}{
+		{"form", true, []string{"id=3", "id=4", "id=5"}, []string{"firstName=Alex", "role=admin"}},
+		{"form", false, []string{"id=3,4,5"}, []string{"id=firstName,Alex,role,admin"}},
+		{"spaceDelimited", false, []string{"id=3%204%205"}, nil},
+		{"pipeDelimited", false, []string{"id=3%7C4%7C5"}, nil},
+		{"deepObject", true, nil, []string{"id[firstName]=Alex", "id[role]=admin"}},
+	}
+
+	for _, tt := range tests {
+		param := styledParameter("query", tt.style, tt.explode)
+		assert.Equal(t, []string{"id=5"}, serializeQueryParameter(param, primitiveValue), "%s explode=%v", tt.style, tt.explode)
+		if tt.array != nil {
+			assert.Equal(t, tt.array, serializeQueryParameter(param, arrayValue), "%s explode=%v", tt.style, tt.explode)
+		}
+		if tt.object != nil {
+			assert.Equal(t, tt.object, serializeQueryParameter(param, objectValue), "%s explode=%v", tt.style, tt.explode)
+		}
+	}
+
+	param := &openapi3.Parameter{Name: "path", In: "query"}
+	assert.Equal(t, []string{"path=%2Fa%2Fb+c%26d"}, serializeQueryParameter(param, "/a/b c&d"))
+	param.AllowReserved = true
+	assert.Equal(t, []string{"path=/a/b+c&d"}, serializeQueryParameter(param, "/a/b c&d"))
+}
+
+func TestSerializeHeaderParameter(t *testing.T) {
+	assert.Equal(t, "5", serializeHeaderParameter(styledParameter("header", "simple", false), primitiveValue))
+	assert.Equal(t, "3,4,5", serializeHeaderParameter(styledParameter("header", "simple", false), arrayValue))
+	assert.Equal(t, "firstName,Alex,role,admin", serializeHeaderParameter(styledParameter("header", "simple", false), objectValue))
+	assert.Equal(t, "firstName=Alex,role=admin", serializeHeaderParameter(styledParameter("header", "simple", true), objectValue))
+}
+
+func TestBuildMCPServerFromSpec_ParameterSerialization(t *testing.T) {
+	var gotPath, gotQuery, gotHeader string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.EscapedPath()
+		gotQuery = r.URL.RawQuery
+		gotHeader = r.Header.Get("X-Ids")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer backend.Close()
+
+	stringArray := openapi3.NewArraySchema().WithItems(openapi3.NewStringSchema()).NewRef()
+	spec := &openapi3.T{Paths: openapi3.NewPaths(
+		openapi3.WithPath("/files/{name}", &openapi3.PathItem{
+			Get: &openapi3.Operation{
+				OperationID: "getFile",
+				Parameters: openapi3.Parameters{
+					{Value: openapi3.NewPathParameter("name").WithSchema(openapi3.NewStringSchema())},
+					{Value: &openapi3.Parameter{Name: "tags", In: "query", Schema: stringArray}},
+					{Value: &openapi3.Parameter{Name: "X-Ids", In: "header", Schema: stringArray}},
+				},
+				Responses: openapi3.NewResponses(),
+			},
+		}),
+	)}
+
+	mcpServer, err := BuildMCPServerFromSpec(spec, &APIConfig{BaseURL: backend.URL})
+	require.NoError(t, err)
+
+	result := callTool(t, mcpServer, "getFile", map[string]any{
+		"name":  "reports/2024 q1.pdf",
+		"tags":  []any{"a", "b"},
+		"X-Ids": []any{"1", "2"},
+	})
+	assert.False(t, result.IsError)
+	assert.Equal(t, "/files/reports%2F2024%20q1.pdf", gotPath)
+	assert.Equal(t, "tags=a&tags=b", gotQuery)
+	assert.Equal(t, "1,2", gotHeader)
+}
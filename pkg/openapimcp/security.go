@@ -0,0 +1,247 @@
+package openapimcp
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/oasdiff/yaml"
+	log "github.com/sirupsen/logrus"
+)
+
+// credentialsEnvPrefix prefixes the environment variables credentials are read from.
+const credentialsEnvPrefix = "OPENAPI_MCP_"
+
+var nonAlphanumeric = regexp.MustCompile(`[^A-Z0-9]+`)
+
+// Credentials holds the secrets used to satisfy a single security scheme.
+type Credentials struct {
+	APIKey   string `json:"apiKey,omitempty"`   // apiKey schemes
+	Token    string `json:"token,omitempty"`    // http bearer schemes, or a pre-issued oauth2 access token
+	Username string `json:"username,omitempty"` // http basic schemes
+	Password string `json:"password,omitempty"` // http basic schemes
+
+	ClientID     string `json:"clientId,omitempty"`     // oauth2 schemes
+	ClientSecret string `json:"clientSecret,omitempty"` // oauth2 schemes
+	RefreshToken string `json:"refreshToken,omitempty"` // oauth2 schemes
+}
+
+// LoadCredentials collects credentials for the security schemes declared in spec,
+// keyed by scheme name. They are read from an optional JSON or YAML file, then
+// from environment variables named OPENAPI_MCP_<SCHEME>_API_KEY, _TOKEN, _USERNAME,
+// _PASSWORD, _CLIENT_ID, _CLIENT_SECRET and _REFRESH_TOKEN, which take precedence. <SCHEME> is the upper-cased scheme name
+// with runs of other characters replaced by underscores.
+func LoadCredentials(path string, spec *openapi3.T) (map[string]Credentials, error) {
+	credentials := map[string]Credentials{}
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read credentials file %s: %w", path, err)
+		}
+		if err := yaml.Unmarshal(data, &credentials); err != nil {
+			return nil, fmt.Errorf("failed to parse credentials file %s: %w", path, err)
+		}
+	}
+
+	if spec.Components == nil {
+		return credentials, nil
+	}
+	for name := range spec.Components.SecuritySchemes {
+		creds := credentials[name]
+		prefix := credentialsEnvPrefix + nonAlphanumeric.ReplaceAllString(strings.ToUpper(name), "_") + "_"
+		if v, ok := os.LookupEnv(prefix + "API_KEY"); ok {
+			creds.APIKey = v
+		}
+		if v, ok := os.LookupEnv(prefix + "TOKEN"); ok {
+			creds.Token = v
+		}
+		if v, ok := os.LookupEnv(prefix + "USERNAME"); ok {
+			creds.Username = v
+		}
+		if v, ok := os.LookupEnv(prefix + "PASSWORD"); ok {
+			creds.Password = v
+		}
+		if v, ok := os.LookupEnv(prefix + "CLIENT_ID"); ok {
+			creds.ClientID = v
+		}
+		if v, ok := os.LookupEnv(prefix + "CLIENT_SECRET"); ok {
+			creds.ClientSecret = v
+		}
+		if v, ok := os.LookupEnv(prefix + "REFRESH_TOKEN"); ok {
+			creds.RefreshToken = v
+		}
+		if creds != (Credentials{}) {
+			credentials[name] = creds
+		}
+	}
+
+	return credentials, nil
+}
+
+// initSecuritySchemes sets up OAuth2 token sources for the declared schemes and logs
+// the ones that cannot be used, either because their type is unsupported or because
+// no credentials are configured.
+func (b *MCPServerBuilder) initSecuritySchemes() {
+	b.tokenSources = map[string]*oauth2TokenSource{}
+
+	names := make([]string, 0, len(b.securitySchemes))
+	for name := range b.securitySchemes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		schemeRef := b.securitySchemes[name]
+		if schemeRef == nil || schemeRef.Value == nil {
+			continue
+		}
+		if schemeRef.Value.Type == "oauth2" {
+			if source := newOAuth2TokenSource(b.config.HTTPClient, schemeRef.Value, b.config.Credentials[name], b.resolveRelativeURL); source != nil {
+				b.tokenSources[name] = source
+			}
+		}
+		if !isSupportedSecurityScheme(schemeRef.Value) {
+			log.Warnf("Security scheme %s of type %s is not supported, requests requiring it are sent unauthenticated", name, schemeRef.Value.Type)
+			continue
+		}
+		if !b.hasCredentials(name, schemeRef.Value) {
+			log.Infof("No credentials configured for security scheme %s", name)
+		}
+	}
+}
+
+func isSupportedSecurityScheme(scheme *openapi3.SecurityScheme) bool {
+	switch scheme.Type {
+	case "apiKey":
+		return scheme.In == "header" || scheme.In == "query" || scheme.In == "cookie"
+	case "http":
+		return strings.EqualFold(scheme.Scheme, "bearer") || strings.EqualFold(scheme.Scheme, "basic")
+	case "oauth2":
+		return scheme.Flows != nil
+	}
+	return false
+}
+
+// hasCredentials reports whether the credentials needed by the scheme are configured.
+func (b *MCPServerBuilder) hasCredentials(name string, scheme *openapi3.SecurityScheme) bool {
+	creds := b.config.Credentials[name]
+	switch scheme.Type {
+	case "apiKey":
+		return creds.APIKey != ""
+	case "http":
+		if strings.EqualFold(scheme.Scheme, "bearer") {
+			return creds.Token != ""
+		}
+		return creds.Username != ""
+	case "oauth2":
+		return creds.Token != "" || b.tokenSources[name] != nil
+	}
+	return false
+}
+
+// securityRequirements returns the requirements of an operation, falling back to
+// the spec-wide requirements when the operation declares none.
+func (b *MCPServerBuilder) securityRequirements(op *openapi3.Operation) openapi3.SecurityRequirements {
+	if op.Security != nil {
+		return *op.Security
+	}
+	return b.defaultSecurity
+}
+
+// appliedToken records an OAuth2 token sent with a request, so it can be
+// discarded if the API rejects it.
+type appliedToken struct {
+	source *oauth2TokenSource
+	scopes []string
+}
+
+// applySecurity authenticates req using the first of the operation's security
+// requirements that can be fully satisfied with the configured credentials.
+// Requests are sent unauthenticated when none can be satisfied. The OAuth2
+// tokens used are returned.
+func (b *MCPServerBuilder) applySecurity(req *http.Request, op *openapi3.Operation) ([]appliedToken, error) {
+	for _, requirement := range b.securityRequirements(op) {
+		if len(requirement) == 0 || !b.canSatisfy(requirement) {
+			continue
+		}
+
+		names := make([]string, 0, len(requirement))
+		for name := range requirement {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		var tokens []appliedToken
+		for _, name := range names {
+			token, err := b.applySecurityScheme(req, name, b.securitySchemes[name].Value, requirement[name])
+			if err != nil {
+				return nil, fmt.Errorf("failed to authenticate with security scheme %s: %w", name, err)
+			}
+			if token != nil {
+				tokens = append(tokens, *token)
+			}
+		}
+		return tokens, nil
+	}
+	return nil, nil
+}
+
+func (b *MCPServerBuilder) canSatisfy(requirement openapi3.SecurityRequirement) bool {
+	for name := range requirement {
+		schemeRef := b.securitySchemes[name]
+		if schemeRef == nil || schemeRef.Value == nil || !isSupportedSecurityScheme(schemeRef.Value) {
+			return false
+		}
+		if !b.hasCredentials(name, schemeRef.Value) {
+			return false
+		}
+	}
+	return true
+}
+
+func (b *MCPServerBuilder) applySecurityScheme(req *http.Request, name string, scheme *openapi3.SecurityScheme, scopes []string) (*appliedToken, error) {
+	creds := b.config.Credentials[name]
+
+	switch scheme.Type {
+	case "apiKey":
+		switch scheme.In {
+		case "header":
+			req.Header.Set(scheme.Name, creds.APIKey)
+		case "query":
+			// Appended so that the serialization of the other query parameters is kept
+			pair := url.QueryEscape(scheme.Name) + "=" + url.QueryEscape(creds.APIKey)
+			if req.URL.RawQuery == "" {
+				req.URL.RawQuery = pair
+			} else {
+				req.URL.RawQuery += "&" + pair
+			}
+		case "cookie":
+			req.AddCookie(&http.Cookie{Name: scheme.Name, Value: creds.APIKey})
+		}
+	case "http":
+		if strings.EqualFold(scheme.Scheme, "bearer") {
+			req.Header.Set("Authorization", "Bearer "+creds.Token)
+		} else {
+			req.SetBasicAuth(creds.Username, creds.Password)
+		}
+	case "oauth2":
+		source := b.tokenSources[name]
+		if source == nil {
+			req.Header.Set("Authorization", "Bearer "+creds.Token)
+			return nil, nil
+		}
+		accessToken, err := source.token(req.Context(), scopes)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		return &appliedToken{source: source, scopes: scopes}, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,151 @@
+package openapimcp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func securitySpec(security openapi3.SecurityRequirements, opSecurity *openapi3.SecurityRequirements) *openapi3.T {
+	return &openapi3.T{
+		Components: &openapi3.Components{
+			SecuritySchemes: openapi3.SecuritySchemes{
+				"header_key": {Value: &openapi3.SecurityScheme{Type: "apiKey", In: "header", Name: "X-API-Key"}},
+				"query_key":  {Value: &openapi3.SecurityScheme{Type: "apiKey", In: "query", Name: "api_key"}},
+				"cookie_key": {Value: &openapi3.SecurityScheme{Type: "apiKey", In: "cookie", Name: "session"}},
+				"bearer":     {Value: &openapi3.SecurityScheme{Type: "http", Scheme: "bearer"}},
+				"basic":      {Value: &openapi3.SecurityScheme{Type: "http", Scheme: "basic"}},
+			},
+		},
+		Security: security,
+		Paths: openapi3.NewPaths(openapi3.WithPath("/secure", &openapi3.PathItem{
+			Get: &openapi3.Operation{OperationID: "secure", Security: opSecurity, Responses: openapi3.NewResponses()},
+		})),
+	}
+}
+
+func TestApplySecurity(t *testing.T) {
+	tests := []struct {
+		name        string
+		security    openapi3.SecurityRequirements
+		opSecurity  *openapi3.SecurityRequirements
+		credentials map[string]Credentials
+		check       func(t *testing.T, r *http.Request)
+	}{
+		{
+			name:        "api key in header",
+			security:    openapi3.SecurityRequirements{{"header_key": {}}},
+			credentials: map[string]Credentials{"header_key": {APIKey: "secret"}},
+			check: func(t *testing.T, r *http.Request) {
+				assert.Equal(t, "secret", r.Header.Get("X-API-Key"))
+			},
+		},
+		{
+			name:        "api key in query and cookie",
+			security:    openapi3.SecurityRequirements{{"query_key": {}, "cookie_key": {}}},
+			credentials: map[string]Credentials{"query_key": {APIKey: "q"}, "cookie_key": {APIKey: "c"}},
+			check: func(t *testing.T, r *http.Request) {
+				assert.Equal(t, "q", r.URL.Query().Get("api_key"))
+				cookie, err := r.Cookie("session")
+				require.NoError(t, err)
+				assert.Equal(t, "c", cookie.Value)
+			},
+		},
+		{
+			name:        "operation security overrides spec security",
+			security:    openapi3.SecurityRequirements{{"header_key": {}}},
+			opSecurity:  &openapi3.SecurityRequirements{{"bearer": {}}},
+			credentials: map[string]Credentials{"header_key": {APIKey: "secret"}, "bearer": {Token: "tok"}},
+			check: func(t *testing.T, r *http.Request) {
+				assert.Equal(t, "Bearer tok", r.Header.Get("Authorization"))
+				assert.Empty(t, r.Header.Get("X-API-Key"))
+			},
+		},
+		{
+			name:        "first satisfiable alternative is used",
+			security:    openapi3.SecurityRequirements{{"bearer": {}}, {"basic": {}}},
+			credentials: map[string]Credentials{"basic": {Username: "user", Password: "pass"}},
+			check: func(t *testing.T, r *http.Request) {
+				username, password, ok := r.BasicAuth()
+				require.True(t, ok)
+				assert.Equal(t, "user", username)
+				assert.Equal(t, "pass", password)
+			},
+		},
+		{
+			name:       "operation without security sends no credentials",
+			security:   openapi3.SecurityRequirements{{"header_key": {}}},
+			opSecurity: &openapi3.SecurityRequirements{},
+			credentials: map[string]Credentials{
+				"header_key": {APIKey: "secret"},
+			},
+			check: func(t *testing.T, r *http.Request) {
+				assert.Empty(t, r.Header.Get("X-API-Key"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received *http.Request
+			backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				received = r
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer backend.Close()
+
+			mcpServer, err := BuildMCPServerFromSpec(securitySpec(tt.security, tt.opSecurity), &APIConfig{
+				BaseURL:     backend.URL,
+				Credentials: tt.credentials,
+			})
+			require.NoError(t, err)
+
+			callTool(t, mcpServer, "secure", map[string]any{})
+			require.NotNil(t, received)
+			tt.check(t, received)
+		})
+	}
+}
+
+func TestApplySecurity_QueryKeyKeepsParameterSerialization(t *testing.T) {
+	var rawQuery string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rawQuery = r.URL.RawQuery
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer backend.Close()
+
+	spec := securitySpec(openapi3.SecurityRequirements{{"query_key": {}}}, nil)
+	spec.Paths.Value("/secure").Get.Parameters = openapi3.Parameters{
+		{Value: &openapi3.Parameter{Name: "path", In: "query", AllowReserved: true, Schema: openapi3.NewStringSchema().NewRef()}},
+		{Value: &openapi3.Parameter{Name: "filter", In: "query", Style: openapi3.SerializationDeepObject, Schema: openapi3.NewObjectSchema().NewRef()}},
+	}
+
+	mcpServer, err := BuildMCPServerFromSpec(spec, &APIConfig{
+		BaseURL:     backend.URL,
+		Credentials: map[string]Credentials{"query_key": {APIKey: "x y"}},
+	})
+	require.NoError(t, err)
+
+	result := callTool(t, mcpServer, "secure", map[string]any{"path": "a/b", "filter": map[string]any{"c": "d"}})
+	require.False(t, result.IsError, result.Content)
+	assert.Equal(t, "path=a/b&filter[c]=d&api_key=x+y", rawQuery)
+}
+
+func TestLoadCredentials(t *testing.T) {
+	path := writeSpecFile(t, "credentials.yaml", "header_key:\n  apiKey: from-file\nbasic:\n  username: file-user\n  password: file-pass\n")
+	t.Setenv("OPENAPI_MCP_HEADER_KEY_API_KEY", "from-env")
+	t.Setenv("OPENAPI_MCP_BEARER_TOKEN", "env-token")
+
+	credentials, err := LoadCredentials(path, securitySpec(nil, nil))
+	require.NoError(t, err)
+
+	assert.Equal(t, "from-env", credentials["header_key"].APIKey)
+	assert.Equal(t, "env-token", credentials["bearer"].Token)
+	assert.Equal(t, Credentials{Username: "file-user", Password: "file-pass"}, credentials["basic"])
+	assert.NotContains(t, credentials, "query_key")
+}
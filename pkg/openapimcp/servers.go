@@ -0,0 +1,82 @@
+package openapimcp
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	log "github.com/sirupsen/logrus"
+)
+
+// resolveBaseURL determines the base URL requests for an operation are sent to.
+// An explicit APIConfig.BaseURL always wins. Otherwise the most specific server
+// list applies (operation, then path item, then spec), server variables are
+// substituted and relative server URLs are resolved against the spec URL.
+func (b *MCPServerBuilder) resolveBaseURL(spec *openapi3.T, pathItem *openapi3.PathItem, op *openapi3.Operation) (string, error) {
+	if b.config.BaseURL != "" {
+		return strings.TrimSuffix(b.config.BaseURL, "/"), nil
+	}
+
+	// Operation and path-item overrides use their first server, the
+	// configured server index only selects among the spec-level servers
+	var server *openapi3.Server
+	switch {
+	case op.Servers != nil && len(*op.Servers) > 0:
+		server = (*op.Servers)[0]
+	case len(pathItem.Servers) > 0:
+		server = pathItem.Servers[0]
+	case len(spec.Servers) > 0:
+		if b.config.ServerIndex < 0 || b.config.ServerIndex >= len(spec.Servers) {
+			return "", fmt.Errorf("server index %d is out of range, spec declares %d server(s)", b.config.ServerIndex, len(spec.Servers))
+		}
+		server = spec.Servers[b.config.ServerIndex]
+	default:
+		// OpenAPI defaults to a single server with URL "/"
+		server = &openapi3.Server{URL: "/"}
+	}
+
+	serverURL, err := expandServerURL(server, b.config.ServerVariables)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(b.resolveRelativeURL(serverURL), "/"), nil
+}
+
+// expandServerURL substitutes server variables in the server URL, preferring the
+// configured overrides over the defaults declared in the spec.
+func expandServerURL(server *openapi3.Server, overrides map[string]string) (string, error) {
+	serverURL := server.URL
+	for name, variable := range server.Variables {
+		value := variable.Default
+		if override, ok := overrides[name]; ok {
+			if len(variable.Enum) > 0 && !contains(variable.Enum, override) {
+				return "", fmt.Errorf("value %q for server variable %s is not one of %v", override, name, variable.Enum)
+			}
+			value = override
+		}
+		serverURL = strings.ReplaceAll(serverURL, "{"+name+"}", value)
+	}
+	return serverURL, nil
+}
+
+// resolveRelativeURL resolves a relative server URL against the spec URL. When the
+// spec was not loaded from a URL there is nothing to resolve against and the URL
+// is returned unchanged.
+func (b *MCPServerBuilder) resolveRelativeURL(serverURL string) string {
+	u, err := url.Parse(serverURL)
+	if err != nil || u.IsAbs() {
+		return serverURL
+	}
+
+	specURL, err := url.Parse(b.config.SpecURL)
+	if err != nil || !specURL.IsAbs() {
+		if serverURL != "/" {
+			log.Warnf("Server URL %s is relative and the spec was not loaded from a URL, set a base URL to call the API", serverURL)
+		}
+		return strings.TrimSuffix(serverURL, "/")
+	}
+
+	return specURL.ResolveReference(u).String()
+}
@@ -0,0 +1,116 @@
+package openapimcp
+
+import (
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveBaseURL(t *testing.T) {
+	regionServer := &openapi3.Server{
+		URL: "https://{region}.api.example.com/{version}",
+		Variables: map[string]*openapi3.ServerVariable{
+			"region":  {Default: "us", Enum: []string{"us", "eu"}},
+			"version": {Default: "v1"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		config   APIConfig
+		servers  openapi3.Servers
+		pathItem *openapi3.PathItem
+		op       *openapi3.Operation
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "server variable defaults",
+			servers:  openapi3.Servers{regionServer},
+			expected: "https://us.api.example.com/v1",
+		},
+		{
+			name:     "server variable overrides",
+			config:   APIConfig{ServerVariables: map[string]string{"region": "eu", "version": "v2"}},
+			servers:  openapi3.Servers{regionServer},
+			expected: "https://eu.api.example.com/v2",
+		},
+		{
+			name:    "server variable override outside enum",
+			config:  APIConfig{ServerVariables: map[string]string{"region": "apac"}},
+			servers: openapi3.Servers{regionServer},
+			wantErr: true,
+		},
+		{
+			name:     "relative server resolved against spec URL",
+			config:   APIConfig{SpecURL: "http://127.0.0.1:8000/docs/openapi.json"},
+			servers:  openapi3.Servers{{URL: "/api/v1/"}},
+			expected: "http://127.0.0.1:8000/api/v1",
+		},
+		{
+			name:     "no servers defaults to spec origin",
+			config:   APIConfig{SpecURL: "http://127.0.0.1:8000/openapi.json"},
+			expected: "http://127.0.0.1:8000",
+		},
+		{
+			name:     "no servers and no spec URL",
+			expected: "",
+		},
+		{
+			name:     "server index",
+			config:   APIConfig{ServerIndex: 1},
+			servers:  openapi3.Servers{{URL: "https://prod.example.com"}, {URL: "https://staging.example.com"}},
+			expected: "https://staging.example.com",
+		},
+		{
+			name:    "server index out of range",
+			config:  APIConfig{ServerIndex: 2},
+			servers: openapi3.Servers{{URL: "https://prod.example.com"}},
+			wantErr: true,
+		},
+		{
+			name:     "path item servers override spec servers",
+			servers:  openapi3.Servers{{URL: "https://prod.example.com"}},
+			pathItem: &openapi3.PathItem{Servers: openapi3.Servers{{URL: "https://files.example.com"}}},
+			expected: "https://files.example.com",
+		},
+		{
+			name:     "operation servers override path item servers",
+			servers:  openapi3.Servers{{URL: "https://prod.example.com"}},
+			pathItem: &openapi3.PathItem{Servers: openapi3.Servers{{URL: "https://files.example.com"}}},
+			op:       &openapi3.Operation{Servers: &openapi3.Servers{{URL: "https://upload.example.com"}}},
+			expected: "https://upload.example.com",
+		},
+		{
+			name:     "explicit base URL wins",
+			config:   APIConfig{BaseURL: "http://localhost:9000/"},
+			servers:  openapi3.Servers{regionServer},
+			op:       &openapi3.Operation{Servers: &openapi3.Servers{{URL: "https://upload.example.com"}}},
+			expected: "http://localhost:9000",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := NewMCPServerBuilder(&tt.config)
+			pathItem := tt.pathItem
+			if pathItem == nil {
+				pathItem = &openapi3.PathItem{}
+			}
+			op := tt.op
+			if op == nil {
+				op = &openapi3.Operation{}
+			}
+
+			baseURL, err := builder.resolveBaseURL(&openapi3.T{Servers: tt.servers}, pathItem, op)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, baseURL)
+		})
+	}
+}
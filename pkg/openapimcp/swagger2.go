@@ -0,0 +1,160 @@
+package openapimcp
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/oasdiff/yaml"
+	log "github.com/sirupsen/logrus"
+)
+
+// isSwagger2 reports whether the raw spec data is a Swagger 2.0 document.
+func isSwagger2(data []byte) bool {
+	var header struct {
+		Swagger string `json:"swagger"`
+	}
+	if err := yaml.Unmarshal(data, &header); err != nil {
+		return false
+	}
+	return strings.HasPrefix(header.Swagger, "2.")
+}
+
+// convertSwagger2 parses a Swagger 2.0 document and converts it to OpenAPI 3.
+// Body and formData parameters become request bodies, consumes/produces become
+// media types, securityDefinitions become security schemes and definitions refs
+// are rewritten to components. Anything that cannot be carried over faithfully
+// is logged as a warning.
+func convertSwagger2(loader *openapi3.Loader, data []byte, location *url.URL) (*openapi3.T, error) {
+	var doc2 openapi2.T
+	if err := yaml.Unmarshal(data, &doc2); err != nil {
+		return nil, fmt.Errorf("failed to parse Swagger 2.0 spec: %w", err)
+	}
+
+	for _, warning := range swagger2Warnings(&doc2) {
+		log.Warnf("Swagger 2.0 conversion: %s", warning)
+	}
+
+	doc3, err := openapi2conv.ToV3WithLoader(&doc2, loader, location)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert Swagger 2.0 spec to OpenAPI 3: %w", err)
+	}
+
+	// Without a host the API is served by the host serving the spec, the
+	// converter only creates servers from the host so the base path is kept
+	// as a relative server URL resolved against the spec URL
+	if doc2.Host == "" && doc2.BasePath != "" && len(doc3.Servers) == 0 {
+		doc3.Servers = openapi3.Servers{{URL: doc2.BasePath}}
+	}
+
+	applyCollectionFormats(&doc2, doc3)
+
+	log.Debugf("Converted Swagger 2.0 spec to OpenAPI %s\n", doc3.OpenAPI)
+	return doc3, nil
+}
+
+// swagger2Warnings lists the parts of a Swagger 2.0 document that are lost or
+// approximated when converting it to OpenAPI 3.
+func swagger2Warnings(doc2 *openapi2.T) []string {
+	var warnings []string
+
+	if doc2.Host == "" {
+		warnings = append(warnings, "spec declares no host, the API is assumed to be served by the host serving the spec")
+	}
+
+	paths := make([]string, 0, len(doc2.Paths))
+	for path := range doc2.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		pathItem := doc2.Paths[path]
+		for _, param := range pathItem.Parameters {
+			warnings = append(warnings, collectionFormatWarnings(path, param)...)
+		}
+
+		operations := pathItem.Operations()
+		methods := make([]string, 0, len(operations))
+		for method := range operations {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+
+		for _, method := range methods {
+			for _, param := range operations[method].Parameters {
+				warnings = append(warnings, collectionFormatWarnings(method+" "+path, param)...)
+			}
+		}
+	}
+
+	return warnings
+}
+
+func collectionFormatWarnings(location string, param *openapi2.Parameter) []string {
+	if param == nil || param.CollectionFormat == "" {
+		return nil
+	}
+	switch {
+	case param.In == "formData":
+		return []string{fmt.Sprintf("%s: collectionFormat %q of form parameter %q is not preserved", location, param.CollectionFormat, param.Name)}
+	case param.CollectionFormat == "tsv":
+		return []string{fmt.Sprintf("%s: collectionFormat \"tsv\" of parameter %q has no OpenAPI 3 equivalent", location, param.Name)}
+	}
+	return nil
+}
+
+// applyCollectionFormats carries Swagger 2.0 collectionFormat values over to the
+// style/explode of the converted parameters, which openapi2conv drops.
+func applyCollectionFormats(doc2 *openapi2.T, doc3 *openapi3.T) {
+	if doc3.Paths == nil {
+		return
+	}
+	for path, pathItem2 := range doc2.Paths {
+		pathItem3 := doc3.Paths.Value(path)
+		if pathItem3 == nil {
+			continue
+		}
+		applyParameterCollectionFormats(pathItem2.Parameters, pathItem3.Parameters)
+		for method, operation2 := range pathItem2.Operations() {
+			if operation3 := pathItem3.GetOperation(method); operation3 != nil {
+				applyParameterCollectionFormats(operation2.Parameters, operation3.Parameters)
+			}
+		}
+	}
+}
+
+func applyParameterCollectionFormats(params2 openapi2.Parameters, params3 openapi3.Parameters) {
+	for _, param2 := range params2 {
+		if param2 == nil || param2.Ref != "" || param2.Type == nil || !param2.Type.Is("array") {
+			continue
+		}
+		param3 := params3.GetByInAndName(param2.In, param2.Name)
+		if param3 == nil {
+			continue
+		}
+
+		// csv is the Swagger 2.0 default, multi matches the OpenAPI 3 default for queries
+		explode := false
+		switch param2.CollectionFormat {
+		case "", "csv":
+			if param2.In == openapi3.ParameterInQuery || param2.In == openapi3.ParameterInCookie {
+				param3.Style = openapi3.SerializationForm
+			}
+		case "ssv":
+			param3.Style = openapi3.SerializationSpaceDelimited
+		case "pipes":
+			param3.Style = openapi3.SerializationPipeDelimited
+		case "multi":
+			param3.Style = openapi3.SerializationForm
+			explode = true
+		default:
+			continue
+		}
+		param3.Explode = &explode
+	}
+}
@@ -0,0 +1,121 @@
+package openapimcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"path"
+	"syscall"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultListenAddr is the address used by the HTTP based transports when none is configured.
+	DefaultListenAddr = ":8080"
+
+	// streamableHTTPEndpoint is the path of the single streamable HTTP endpoint when no base path is configured.
+	streamableHTTPEndpoint = "/mcp"
+
+	// shutdownTimeout bounds how long open client connections are given to drain on shutdown.
+	shutdownTimeout = 10 * time.Second
+)
+
+// serveSSE starts an SSE server for mcpServer and blocks until it fails or the
+// process receives SIGINT/SIGTERM, in which case it shuts down gracefully.
+func serveSSE(mcpServer *server.MCPServer, config GeneratorConfig) error {
+	opts := []server.SSEOption{}
+	if config.BasePath != "" {
+		opts = append(opts, server.WithStaticBasePath(config.BasePath))
+	}
+	if config.KeepAlive > 0 {
+		opts = append(opts, server.WithKeepAlive(true), server.WithKeepAliveInterval(config.KeepAlive))
+	}
+
+	sseServer := server.NewSSEServer(mcpServer, opts...)
+
+	addr := config.ListenAddr
+	if addr == "" {
+		addr = DefaultListenAddr
+	}
+
+	log.Infof("Starting MCP SSE server on %s (endpoint: %s)", addr, sseServer.CompleteSsePath())
+	return runUntilSignal(func() error { return sseServer.Start(addr) }, sseServer.Shutdown)
+}
+
+// serveStreamableHTTP starts a streamable HTTP server for mcpServer and blocks until
+// it fails or the process receives SIGINT/SIGTERM, in which case it shuts down gracefully.
+// Clients POST JSON-RPC messages to a single endpoint, may open a GET stream for
+// server notifications, and are tracked through the Mcp-Session-Id header.
+func serveStreamableHTTP(mcpServer *server.MCPServer, config GeneratorConfig) error {
+	handler, endpoint := newStreamableHTTPHandler(mcpServer, config)
+
+	addr := config.ListenAddr
+	if addr == "" {
+		addr = DefaultListenAddr
+	}
+	httpServer := &http.Server{Addr: addr, Handler: handler}
+
+	log.Infof("Starting MCP streamable HTTP server on %s (endpoint: %s)", addr, endpoint)
+	return runUntilSignal(func() error { return httpServer.ListenAndServe() }, httpServer.Shutdown)
+}
+
+// newStreamableHTTPHandler mounts the streamable HTTP endpoint of mcpServer
+// and returns the handler along with the endpoint path. The endpoint is the
+// base path itself, or /mcp when no base path is configured.
+func newStreamableHTTPHandler(mcpServer *server.MCPServer, config GeneratorConfig) (http.Handler, string) {
+	endpoint := streamableHTTPEndpoint
+	if config.BasePath != "" && config.BasePath != "/" {
+		endpoint = path.Join("/", config.BasePath)
+	}
+
+	opts := []server.StreamableHTTPOption{
+		server.WithEndpointPath(endpoint),
+	}
+	if config.KeepAlive > 0 {
+		opts = append(opts, server.WithHeartbeatInterval(config.KeepAlive))
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(endpoint, server.NewStreamableHTTPServer(mcpServer, opts...))
+	return mux, endpoint
+}
+
+// runUntilSignal runs start in the background and waits for it to return or for
+// an interrupt signal. On a signal, shutdown is invoked with a bounded timeout.
+func runUntilSignal(start func() error, shutdown func(context.Context) error) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- start()
+	}()
+
+	select {
+	case err := <-errCh:
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	case <-ctx.Done():
+		log.Info("Shutdown signal received, stopping MCP server...")
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to shut down MCP server: %w", err)
+	}
+
+	if err := <-errCh; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package openapimcp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewStreamableHTTPHandler_EndpointPath(t *testing.T) {
+	tests := []struct {
+		basePath string
+		endpoint string
+		notFound string
+	}{
+		{basePath: "", endpoint: "/mcp", notFound: "/mcp/mcp"},
+		{basePath: "/mcp", endpoint: "/mcp", notFound: "/mcp/mcp"},
+		{basePath: "api/v1/", endpoint: "/api/v1", notFound: "/api/v1/mcp"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.basePath, func(t *testing.T) {
+			mcpServer := server.NewMCPServer("test", "1.0.0")
+			handler, endpoint := newStreamableHTTPHandler(mcpServer, GeneratorConfig{BasePath: tt.basePath})
+			assert.Equal(t, tt.endpoint, endpoint)
+
+			httpServer := httptest.NewServer(handler)
+			defer httpServer.Close()
+
+			initialize := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`
+			post := func(path string) int {
+				req, err := http.NewRequest(http.MethodPost, httpServer.URL+path, strings.NewReader(initialize))
+				require.NoError(t, err)
+				req.Header.Set("Content-Type", "application/json")
+				req.Header.Set("Accept", "application/json, text/event-stream")
+				resp, err := http.DefaultClient.Do(req)
+				require.NoError(t, err)
+				//nolint
+				resp.Body.Close()
+				return resp.StatusCode
+			}
+
+			assert.Equal(t, http.StatusOK, post(tt.endpoint))
+			assert.Equal(t, http.StatusNotFound, post(tt.notFound))
+		})
+	}
+}
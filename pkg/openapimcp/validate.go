@@ -0,0 +1,139 @@
+package openapimcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// validateParameter returns the violations of a parameter value against the
+// parameter's schema
+func validateParameter(param *openapi3.Parameter, value any) []string {
+	if param.Schema == nil || param.Schema.Value == nil {
+		return nil
+	}
+
+	err := param.Schema.Value.VisitJSON(value, openapi3.MultiErrors())
+	return schemaViolations([]string{param.Name}, err)
+}
+
+// validateBody returns the violations of the request body fields against the
+// request body schema. Hidden properties are never required from the caller,
+// and binary properties are checked when their file references are resolved.
+func validateBody(schemaRef *openapi3.SchemaRef, fields map[string]any) []string {
+	if schemaRef == nil || schemaRef.Value == nil {
+		return nil
+	}
+
+	schema := *schemaRef.Value
+	schema.Required = nil
+	for _, name := range schemaRef.Value.Required {
+		if prop := schemaRef.Value.Properties[name]; prop != nil && prop.Value != nil && isHidden(prop.Value.Extensions) {
+			if _, ok := fields[name]; !ok {
+				continue
+			}
+		}
+		schema.Required = append(schema.Required, name)
+	}
+
+	schema.Properties = make(openapi3.Schemas, len(schemaRef.Value.Properties))
+	for name, prop := range schemaRef.Value.Properties {
+		switch {
+		case isBinarySchema(prop):
+			prop = openapi3.NewSchemaRef("", &openapi3.Schema{})
+		case prop != nil && prop.Value != nil && prop.Value.Type.Is("array") && isBinarySchema(prop.Value.Items):
+			array := *prop.Value
+			array.Items = openapi3.NewSchemaRef("", &openapi3.Schema{})
+			prop = array.NewRef()
+		}
+		schema.Properties[name] = prop
+	}
+
+	err := schema.VisitJSON(fields, openapi3.MultiErrors(), openapi3.VisitAsRequest())
+	return schemaViolations(nil, err)
+}
+
+// schemaViolations flattens a schema validation error into one readable
+// violation per failed constraint, prefixed with the path of the argument
+func schemaViolations(path []string, err error) []string {
+	switch e := err.(type) {
+	case nil:
+		return nil
+	case openapi3.MultiError:
+		violations := []string{}
+		for _, err := range e {
+			violations = append(violations, schemaViolations(path, err)...)
+		}
+		return violations
+	case *openapi3.SchemaError:
+		errPath := append(append([]string{}, path...), e.JSONPointer()...)
+		if e.Origin != nil {
+			return schemaViolations(errPath, e.Origin)
+		}
+		reason := e.Reason
+		switch {
+		case e.SchemaField == "required":
+			// The path already names the missing property
+			reason = "required field is missing"
+		case reason == "":
+			reason = fmt.Sprintf("doesn't match schema %q", e.SchemaField)
+		}
+		return []string{formatViolation(errPath, reason)}
+	default:
+		return []string{formatViolation(path, err.Error())}
+	}
+}
+
+func formatViolation(path []string, reason string) string {
+	if len(path) == 0 {
+		return reason
+	}
+	return strings.Join(path, ".") + ": " + reason
+}
+
+// invalidArgumentsResult reports the violations to the model as a tool error
+func invalidArgumentsResult(violations []string) *mcp.CallToolResult {
+	return mcp.NewToolResultError("Invalid arguments:\n- " + strings.Join(violations, "\n- "))
+}
+
+// validateResponse returns the mismatches between an API response and the
+// responses the operation declares: the status code, the content type and the
+// JSON body schema
+func validateResponse(op *openapi3.Operation, resp *apiResponse) []string {
+	response := documentedResponse(op, resp.StatusCode)
+	if response == nil {
+		return []string{fmt.Sprintf("status %d is not declared for the operation", resp.StatusCode)}
+	}
+	if len(resp.Body) == 0 {
+		return nil
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if len(response.Content) == 0 {
+		return []string{fmt.Sprintf("status %d declares no response body but one was returned", resp.StatusCode)}
+	}
+	mediaType := response.Content.Get(contentType)
+	if mediaType == nil {
+		return []string{fmt.Sprintf("content type %q is not declared for status %d", contentType, resp.StatusCode)}
+	}
+	if mediaType.Schema == nil || mediaType.Schema.Value == nil || !strings.Contains(contentType, "json") {
+		return nil
+	}
+
+	var body any
+	if err := json.Unmarshal(resp.Body, &body); err != nil {
+		return []string{fmt.Sprintf("response body is not valid JSON: %v", err)}
+	}
+
+	err := mediaType.Schema.Value.VisitJSON(body, openapi3.MultiErrors(), openapi3.VisitAsResponse())
+	return schemaViolations([]string{"body"}, err)
+}
+
+// withResponseWarnings appends the response validation warnings to the result
+func withResponseWarnings(result *mcp.CallToolResult, warnings []string) *mcp.CallToolResult {
+	result.Content = append(result.Content, mcp.NewTextContent("Response does not match the spec:\n- "+strings.Join(warnings, "\n- ")))
+	return result
+}
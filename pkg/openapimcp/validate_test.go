@@ -0,0 +1,160 @@
+package openapimcp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateParameter(t *testing.T) {
+	limit := &openapi3.Parameter{Name: "limit", In: "query", Schema: openapi3.NewIntegerSchema().WithMin(1).WithMax(100).NewRef()}
+	status := &openapi3.Parameter{Name: "status", In: "query", Schema: openapi3.NewStringSchema().WithEnum("available", "sold").NewRef()}
+	tags := &openapi3.Parameter{Name: "tags", In: "query", Schema: openapi3.NewArraySchema().WithItems(openapi3.NewStringSchema().WithPattern("^[a-z]+$")).NewRef()}
+
+	assert.Empty(t, validateParameter(limit, float64(10)))
+	assert.Equal(t, []string{"limit: number must be at most 100"}, validateParameter(limit, float64(500)))
+	assert.Equal(t, []string{"limit: value must be an integer"}, validateParameter(limit, 1.5))
+	assert.Equal(t, []string{`status: value is not one of the allowed values ["available","sold"]`}, validateParameter(status, "pending"))
+	assert.Equal(t, []string{`tags.1: string doesn't match the regular expression "^[a-z]+$"`}, validateParameter(tags, []any{"ok", "NOT"}))
+}
+
+func TestValidateBody(t *testing.T) {
+	schema := openapi3.NewObjectSchema().
+		WithProperty("name", openapi3.NewStringSchema()).
+		WithProperty("age", openapi3.NewIntegerSchema().WithMin(0)).
+		WithProperty("address", openapi3.NewObjectSchema().
+			WithProperty("zip", openapi3.NewStringSchema().WithMaxLength(5))).
+		WithProperty("tenant", &openapi3.Schema{Type: &openapi3.Types{"string"}, Extensions: map[string]any{"x-mcp-hidden": true}})
+	schema.Required = []string{"name", "tenant"}
+	schemaRef := schema.NewRef()
+
+	assert.Empty(t, validateBody(schemaRef, map[string]any{"name": "Rex"}))
+
+	violations := validateBody(schemaRef, map[string]any{
+		"age":     float64(-1),
+		"address": map[string]any{"zip": "1234567"},
+	})
+	assert.ElementsMatch(t, []string{
+		"name: required field is missing",
+		"age: number must be at least 0",
+		"address.zip: maximum string length is 5",
+	}, violations)
+}
+
+func TestBuildMCPServerFromSpec_InvalidArguments(t *testing.T) {
+	called := false
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer backend.Close()
+
+	body := openapi3.NewObjectSchema().WithProperty("name", openapi3.NewStringSchema().WithMinLength(1))
+	body.Required = []string{"name"}
+	spec := &openapi3.T{Paths: openapi3.NewPaths(
+		openapi3.WithPath("/pets/{id}", &openapi3.PathItem{
+			Put: &openapi3.Operation{
+				OperationID: "updatePet",
+				Parameters: openapi3.Parameters{
+					{Value: openapi3.NewPathParameter("id").WithSchema(openapi3.NewIntegerSchema())},
+				},
+				RequestBody: &openapi3.RequestBodyRef{Value: openapi3.NewRequestBody().WithJSONSchema(body)},
+				Responses:   openapi3.NewResponses(),
+			},
+		}),
+	)}
+
+	mcpServer, err := BuildMCPServerFromSpec(spec, &APIConfig{BaseURL: backend.URL})
+	require.NoError(t, err)
+
+	result := callTool(t, mcpServer, "updatePet", map[string]any{"id": "seven"})
+	assert.True(t, result.IsError)
+	assert.Equal(t, "Invalid arguments:\n- id: value must be an integer\n- name: required field is missing", result.Content[0].(mcp.TextContent).Text)
+	assert.False(t, called)
+
+	result = callTool(t, mcpServer, "updatePet", map[string]any{"id": float64(7), "name": "Rex"})
+	assert.False(t, result.IsError)
+	assert.True(t, called)
+}
+
+func TestValidateResponse(t *testing.T) {
+	pet := openapi3.NewObjectSchema().WithProperty("id", openapi3.NewIntegerSchema())
+	pet.Required = []string{"id"}
+	op := &openapi3.Operation{Responses: openapi3.NewResponses(
+		openapi3.WithName("200", openapi3.NewResponse().WithDescription("OK").WithJSONSchema(pet)),
+		openapi3.WithName("204", openapi3.NewResponse().WithDescription("No Content")),
+	)}
+	jsonHeader := http.Header{"Content-Type": {"application/json"}}
+
+	tests := []struct {
+		name     string
+		resp     *apiResponse
+		expected []string
+	}{
+		{
+			name: "matching response",
+			resp: &apiResponse{StatusCode: 200, Header: jsonHeader, Body: []byte(`{"id":1}`)},
+		},
+		{
+			name:     "undeclared status",
+			resp:     &apiResponse{StatusCode: 500, Header: jsonHeader, Body: []byte(`{}`)},
+			expected: []string{"status 500 is not declared for the operation"},
+		},
+		{
+			name:     "undeclared content type",
+			resp:     &apiResponse{StatusCode: 200, Header: http.Header{"Content-Type": {"text/html"}}, Body: []byte("<html/>")},
+			expected: []string{`content type "text/html" is not declared for status 200`},
+		},
+		{
+			name:     "undeclared body",
+			resp:     &apiResponse{StatusCode: 204, Header: jsonHeader, Body: []byte(`{}`)},
+			expected: []string{"status 204 declares no response body but one was returned"},
+		},
+		{
+			name:     "body schema mismatch",
+			resp:     &apiResponse{StatusCode: 200, Header: jsonHeader, Body: []byte(`{"id":"one"}`)},
+			expected: []string{"body.id: value must be an integer"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, validateResponse(op, tt.resp))
+		})
+	}
+}
+
+func TestBuildMCPServerFromSpec_ValidateResponses(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"one"}`))
+	}))
+	defer backend.Close()
+
+	spec := &openapi3.T{Paths: openapi3.NewPaths(
+		openapi3.WithPath("/pets/1", &openapi3.PathItem{
+			Get: &openapi3.Operation{OperationID: "getPet", Responses: openapi3.NewResponses(
+				openapi3.WithName("200", openapi3.NewResponse().WithDescription("OK").
+					WithJSONSchema(openapi3.NewObjectSchema().WithProperty("id", openapi3.NewIntegerSchema()))),
+			)},
+		}),
+	)}
+
+	for _, validate := range []bool{false, true} {
+		mcpServer, err := BuildMCPServerFromSpec(spec, &APIConfig{BaseURL: backend.URL, ValidateResponses: validate})
+		require.NoError(t, err)
+
+		result := callTool(t, mcpServer, "getPet", map[string]any{})
+		if !validate {
+			assert.Len(t, result.Content, 1)
+			continue
+		}
+		require.Len(t, result.Content, 2)
+		assert.Equal(t, "Response does not match the spec:\n- body.id: value must be an integer", result.Content[1].(mcp.TextContent).Text)
+	}
+}